@@ -3,6 +3,7 @@ package appbase
 import (
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/robert-pkg/micro-go/log"
@@ -15,6 +16,31 @@ type Application interface {
 	OnQuit()
 }
 
+// Reloader 支持重新加载配置的应用程序, 收到 SIGHUP 时调用 Reload.
+// 日志配置由 SetLogReloader 重新加载, Reload 中不需要再重新加载日志配置
+type Reloader interface {
+	Reload()
+}
+
+var logReloader atomic.Value // NOTE: stored func() error
+
+// SetLogReloader 设置后, 收到 SIGHUP 时调用 f 重新加载日志配置, 应用不需要自己实现 Reloader.
+// appbase 不依赖具体的日志实现, e.g. f 重新解析配置文件, 并把其中的 log 配置传给 zap.ReloadByConfig.
+func SetLogReloader(f func() error) {
+	logReloader.Store(f)
+}
+
+func reloadLog() {
+	f, _ := logReloader.Load().(func() error)
+	if f == nil {
+		return
+	}
+
+	if err := f(); err != nil {
+		log.Error("reload log config fail", "err", err)
+	}
+}
+
 // WaitForQuit .
 func WaitForQuit(app Application) {
 
@@ -29,7 +55,10 @@ func WaitForQuit(app Application) {
 			app.OnQuit()
 			return
 		case syscall.SIGHUP:
-			// TODO reload
+			reloadLog()
+			if r, ok := app.(Reloader); ok {
+				r.Reload()
+			}
 		}
 	}
 
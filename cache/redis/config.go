@@ -13,4 +13,5 @@ type Config struct {
 	DBNumber  int    `yaml:"db_number"`
 
 	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	Trace       bool          `yaml:"trace"` // create spans for commands of conns got by GetConn
 }
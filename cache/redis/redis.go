@@ -6,10 +6,16 @@ import (
 	"github.com/garyburd/redigo/redis"
 )
 
+// Pool redis.Pool with the trace setting of Config, see GetConn.
+type Pool struct {
+	*redis.Pool
+	trace bool
+}
+
 // NewRedisPool .
-func NewRedisPool(cfg *Config) *redis.Pool {
+func NewRedisPool(cfg *Config) *Pool {
 
-	return &redis.Pool{
+	pool := &redis.Pool{
 		MaxIdle:     cfg.MaxIdle,
 		MaxActive:   cfg.MaxActive,
 		IdleTimeout: cfg.IdleTimeout,
@@ -42,4 +48,6 @@ func NewRedisPool(cfg *Config) *redis.Pool {
 			return err
 		},
 	}
+
+	return &Pool{Pool: pool, trace: cfg.Trace}
 }
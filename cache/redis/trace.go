@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/robert-pkg/micro-go/trace"
+)
+
+// maxCommandLen commands are user input, e.g. "EVALSHA", keep tags short
+const maxCommandLen = 64
+
+// tracedConn create a span for each Do
+type tracedConn struct {
+	redis.Conn
+	ctx context.Context
+}
+
+// GetConn get a conn from pool, its Do creates child spans of the span in ctx if Config.Trace is set.
+// commands sent by Send are not traced.
+//
+//	conn := redis.GetConn(ctx, pool)
+//	defer conn.Close()
+func GetConn(ctx context.Context, pool *Pool) redis.Conn {
+	if !pool.trace {
+		return pool.Get()
+	}
+	return WithContext(ctx, pool.Get())
+}
+
+// WithContext wrap c, its Do creates child spans of the span in ctx.
+func WithContext(ctx context.Context, c redis.Conn) redis.Conn {
+	return &tracedConn{Conn: c, ctx: ctx}
+}
+
+func (c *tracedConn) Do(commandName string, args ...interface{}) (reply interface{}, err error) {
+	// 只记录在 trace 中的调用; commandName 为空时只是 flush
+	parent := trace.SpanFromContext(c.ctx)
+	if parent == nil || len(commandName) <= 0 {
+		return c.Conn.Do(commandName, args...)
+	}
+
+	cmd := trace.Truncate(strings.ToUpper(commandName), maxCommandLen)
+	span := parent.Tracer().StartSpan(
+		"redis."+cmd,
+		opentracing.ChildOf(parent.Context()),
+		opentracing.Tag{Key: string(ext.Component), Value: "redigo"},
+		ext.SpanKindRPCClient,
+	)
+	defer span.Finish()
+
+	// 不记录参数, 只记录命令及 key 的个数
+	ext.DBType.Set(span, "redis")
+	ext.DBStatement.Set(span, cmd)
+	span.SetTag("redis.key_count", keyCount(cmd, len(args)))
+
+	reply, err = c.Conn.Do(commandName, args...)
+	if err != nil && err != redis.ErrNil {
+		trace.SetError(span, err)
+	}
+	return reply, err
+}
+
+// keyCount number of keys of common commands, most commands have one key
+func keyCount(cmd string, argc int) int {
+	switch cmd {
+	case "MGET", "DEL", "EXISTS", "UNLINK", "TOUCH", "WATCH":
+		return argc
+	case "MSET", "MSETNX":
+		return argc / 2
+	case "PING", "INFO", "SELECT", "AUTH", "MULTI", "EXEC", "DISCARD", "UNWATCH", "FLUSHDB", "DBSIZE", "TIME":
+		return 0
+	}
+
+	if argc > 0 {
+		return 1
+	}
+	return 0
+}
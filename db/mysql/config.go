@@ -10,4 +10,5 @@ type Config struct {
 	Active      int           `yaml:"active"`      // pool
 	Idle        int           `yaml:"idle"`        // pool
 	MaxLiefTime time.Duration `yaml:"maxLiefTime"` // connect max life time.
+	Trace       bool          `yaml:"trace"`       // create spans for statements, see WithContext.
 }
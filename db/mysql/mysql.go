@@ -40,6 +40,10 @@ func InitDb(c *Config) (*gorm.DB, error) {
 	// 如果设置为true,`User`的默认表名为`user`,使用`TableName`设置的表名不受影响
 	// ggorm.SingularTable(true)
 
+	if c.Trace {
+		RegisterTraceCallbacks(ggorm)
+	}
+
 	// 打开调试SQL模式
 	ggorm.LogMode(true)
 	ggorm.SetLogger(&logImpl{})
@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/robert-pkg/micro-go/trace"
+)
+
+const (
+	contextKey = "micro-go:context"
+	spanKey    = "micro-go:span"
+
+	maxStatementLen = 1024
+)
+
+var (
+	// 字符串及数字字面量, 原生 SQL 中可能带有参数值
+	stringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"`)
+	numberLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// WithContext return db carrying ctx, statements executed by it create child spans of the span in ctx.
+//
+//	mysql.WithContext(db, ctx).Where("id = ?", id).First(&user)
+func WithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.Set(contextKey, ctx)
+}
+
+// RegisterTraceCallbacks create a span for each statement executed by db with context, see WithContext.
+func RegisterTraceCallbacks(db *gorm.DB) {
+	cb := db.Callback()
+
+	cb.Create().Before("gorm:create").Register("micro-go:trace_before_create", beforeCallback("create"))
+	cb.Create().After("gorm:create").Register("micro-go:trace_after_create", afterCallback)
+	cb.Query().Before("gorm:query").Register("micro-go:trace_before_query", beforeCallback("query"))
+	cb.Query().After("gorm:query").Register("micro-go:trace_after_query", afterCallback)
+	cb.Update().Before("gorm:update").Register("micro-go:trace_before_update", beforeCallback("update"))
+	cb.Update().After("gorm:update").Register("micro-go:trace_after_update", afterCallback)
+	cb.Delete().Before("gorm:delete").Register("micro-go:trace_before_delete", beforeCallback("delete"))
+	cb.Delete().After("gorm:delete").Register("micro-go:trace_after_delete", afterCallback)
+	cb.RowQuery().Before("gorm:row_query").Register("micro-go:trace_before_row_query", beforeCallback("row_query"))
+	cb.RowQuery().After("gorm:row_query").Register("micro-go:trace_after_row_query", afterCallback)
+}
+
+func beforeCallback(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		v, ok := scope.Get(contextKey)
+		if !ok {
+			return
+		}
+
+		ctx, ok := v.(context.Context)
+		if !ok {
+			return
+		}
+
+		// 只记录在 trace 中的调用
+		parent := trace.SpanFromContext(ctx)
+		if parent == nil {
+			return
+		}
+
+		span := parent.Tracer().StartSpan(
+			"mysql."+operation,
+			opentracing.ChildOf(parent.Context()),
+			opentracing.Tag{Key: string(ext.Component), Value: "gorm"},
+			ext.SpanKindRPCClient,
+		)
+		ext.DBType.Set(span, "mysql")
+		scope.InstanceSet(spanKey, span)
+	}
+}
+
+func afterCallback(scope *gorm.Scope) {
+	v, ok := scope.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+
+	span, ok := v.(opentracing.Span)
+	if !ok {
+		return
+	}
+	defer span.Finish()
+
+	// SQL 中的参数是占位符, 不记录参数值
+	ext.DBStatement.Set(span, sanitize(scope.SQL))
+	span.SetTag("db.table", scope.TableName())
+	span.SetTag("db.rows_affected", scope.DB().RowsAffected)
+
+	if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+		trace.SetError(span, scope.DB().Error)
+	}
+}
+
+// sanitize replace literals with ?, collapse whitespaces and truncate long statements
+func sanitize(sql string) string {
+	sql = stringLiteral.ReplaceAllString(sql, "?")
+	sql = numberLiteral.ReplaceAllString(sql, "?")
+	sql = strings.Join(strings.Fields(sql), " ")
+	return trace.Truncate(sql, maxStatementLen)
+}
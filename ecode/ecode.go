@@ -2,31 +2,171 @@ package ecode
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
 )
 
+// CommonModule module name of the common ecode defined in this package.
+const CommonModule = "common"
+
 var (
-	_messages atomic.Value           // NOTE: stored map[string]map[int]string
-	_codes    = map[int32]struct{}{} // register codes.
+	_mu         sync.Mutex             // guard _codes, _owners, _duplicated and writes of _messages
+	_messages   atomic.Value           // NOTE: stored map[int32]string
+	_codes      = map[int32]struct{}{} // register codes.
+	_owners     = map[int32]string{}   // code -> module registered the message
+	_duplicated = map[int32]struct{}{} // codes passed to New more than once
 )
 
-// Register register ecode message map.
+func init() {
+	cm := make(map[int32]string, len(defaultCodeMap))
+	for k, v := range defaultCodeMap {
+		cm[k] = v
+		_owners[k] = CommonModule
+	}
+	_messages.Store(cm)
+}
+
+// ConflictError code is already registered by another module.
+type ConflictError struct {
+	Code        int32
+	Module      string
+	ExistModule string
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.ExistModule) <= 0 {
+		if len(e.Module) <= 0 {
+			return fmt.Sprintf("ecode: %d already exist", e.Code)
+		}
+		return fmt.Sprintf("ecode: %d of module %q is created by New more than once", e.Code, e.Module)
+	}
+	return fmt.Sprintf("ecode: %d of module %q already registered by module %q", e.Code, e.Module, e.ExistModule)
+}
+
+// Register register ecode message map, merge with existing messages.
+// it can be called many times, later messages overwrite earlier ones.
+// cm is not modified.
 func Register(cm map[int32]string) {
+	_mu.Lock()
+	defer _mu.Unlock()
 
-	for k, v := range defaultCodeMap {
-		if _, ok := cm[k]; !ok {
-			cm[k] = v
+	mergeMessages(cm)
+}
+
+// RegisterModule register ecode messages of module, merge with existing messages.
+// it returns *ConflictError if any code is already registered by another module,
+// or passed to New more than once, and none of messages are registered in that case.
+// messages of common ecode can be overwritten by any module.
+func RegisterModule(module string, cm map[int32]string) error {
+	_mu.Lock()
+	defer _mu.Unlock()
+
+	for code := range cm {
+		if _, ok := _duplicated[code]; ok {
+			return &ConflictError{Code: code, Module: module}
+		}
+		if owner, ok := _owners[code]; ok && owner != module && owner != CommonModule {
+			return &ConflictError{Code: code, Module: module, ExistModule: owner}
 		}
 	}
 
-	_messages.Store(cm)
+	for code := range cm {
+		if _owners[code] != CommonModule {
+			_owners[code] = module
+		}
+	}
+	mergeMessages(cm)
+
+	return nil
+}
+
+// CodeInfo registered ecode, used for generating error-code documentation.
+type CodeInfo struct {
+	Code     int32             `json:"code" yaml:"code"`
+	Module   string            `json:"module" yaml:"module"`
+	Message  string            `json:"message" yaml:"message"`
+	Messages map[string]string `json:"messages,omitempty" yaml:"messages,omitempty"` // locale -> message
+	// Duplicated code is passed to New more than once
+	Duplicated bool `json:"duplicated,omitempty" yaml:"duplicated,omitempty"`
+}
+
+// List return all registered codes ordered by code.
+func List() []CodeInfo {
+	_mu.Lock()
+	all := make(map[int32]struct{}, len(_codes)+len(_owners))
+	for code := range _codes {
+		all[code] = struct{}{}
+	}
+	for code := range _owners {
+		all[code] = struct{}{}
+	}
+	owners := make(map[int32]string, len(_owners))
+	for k, v := range _owners {
+		owners[k] = v
+	}
+	duplicated := make(map[int32]struct{}, len(_duplicated))
+	for code := range _duplicated {
+		duplicated[code] = struct{}{}
+	}
+	_mu.Unlock()
+
+	cm, _ := _messages.Load().(map[int32]string)
+	lm, _ := _localeMessages.Load().(map[string]map[int32]string)
+	for _, m := range lm {
+		for code := range m {
+			all[code] = struct{}{}
+		}
+	}
+	for code := range cm {
+		all[code] = struct{}{}
+	}
+
+	list := make([]CodeInfo, 0, len(all))
+	for code := range all {
+		info := CodeInfo{
+			Code:    code,
+			Module:  owners[code],
+			Message: cm[code],
+		}
+		if _, ok := duplicated[code]; ok {
+			info.Duplicated = true
+		}
+		for locale, m := range lm {
+			if msg, ok := m[code]; ok {
+				if info.Messages == nil {
+					info.Messages = make(map[string]string)
+				}
+				info.Messages[locale] = msg
+			}
+		}
+		list = append(list, info)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// mergeMessages copy on write, caller must hold _mu.
+func mergeMessages(cm map[int32]string) {
+	old, _ := _messages.Load().(map[int32]string)
+	ncm := make(map[int32]string, len(old)+len(cm))
+	for k, v := range old {
+		ncm[k] = v
+	}
+	for k, v := range cm {
+		ncm[k] = v
+	}
+	_messages.Store(ncm)
 }
 
 // New new a ecode.Codes by int value.
-// NOTE: ecode must unique in global, the New will check repeat and then panic.
+// NOTE: ecode must unique in global. a repeated code does not panic at package init,
+// it is recorded, then reported by RegisterModule and List (CodeInfo.Duplicated).
+// TryNew is the supported way to check it at once.
 func New(e int32) Code {
 	if e <= 0 {
 		panic("business ecode must greater than zero")
@@ -34,12 +174,33 @@ func New(e int32) Code {
 	return add(e)
 }
 
+// TryNew new a ecode.Codes by int value, return *ConflictError if it already exist.
+func TryNew(e int32) (Code, error) {
+	if e <= 0 {
+		return 0, errors.New("business ecode must greater than zero")
+	}
+	return tryAdd(e)
+}
+
+// add is tryAdd recording the repeated code instead of returning error
 func add(e int32) Code {
+	if _, err := tryAdd(e); err != nil {
+		_mu.Lock()
+		_duplicated[e] = struct{}{}
+		_mu.Unlock()
+	}
+	return Int32(e)
+}
+
+func tryAdd(e int32) (Code, error) {
+	_mu.Lock()
+	defer _mu.Unlock()
+
 	if _, ok := _codes[e]; ok {
-		panic(fmt.Sprintf("ecode: %d already exist", e))
+		return 0, &ConflictError{Code: e}
 	}
 	_codes[e] = struct{}{}
-	return Int32(e)
+	return Int32(e), nil
 }
 
 // Codes ecode error interface which has a code & message.
@@ -117,6 +278,10 @@ func Cause(e error) Codes {
 	if ok {
 		return ec
 	}
+	// wrapped by fmt.Errorf("%w")
+	if errors.As(e, &ec) {
+		return ec
+	}
 	return String(e.Error())
 }
 
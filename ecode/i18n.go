@@ -0,0 +1,213 @@
+package ecode
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultLocale locale of messages passed to Register.
+const DefaultLocale = "zh-CN"
+
+var (
+	_localeMessages atomic.Value // NOTE: stored map[string]map[int32]string, key is normalized locale
+	_fallbacks      atomic.Value // NOTE: stored map[string][]string, key is normalized locale
+)
+
+// normalizeLocale "en_US" -> "en-us"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// RegisterLocale register ecode messages for locale, merge with existing messages of the locale.
+func RegisterLocale(locale string, cm map[int32]string) {
+	locale = normalizeLocale(locale)
+
+	_mu.Lock()
+	defer _mu.Unlock()
+
+	old, _ := _localeMessages.Load().(map[string]map[int32]string)
+	lm := make(map[string]map[int32]string, len(old)+1)
+	for k, v := range old {
+		lm[k] = v
+	}
+
+	ncm := make(map[int32]string, len(lm[locale])+len(cm))
+	for k, v := range lm[locale] {
+		ncm[k] = v
+	}
+	for k, v := range cm {
+		ncm[k] = v
+	}
+	lm[locale] = ncm
+
+	_localeMessages.Store(lm)
+}
+
+// SetFallbacks set fallback chain of locale, e.g. SetFallbacks("zh-TW", "zh-HK", "zh-CN").
+// the base language ("en" for "en-US") and default messages are always tried at last.
+func SetFallbacks(locale string, fallbacks ...string) {
+	locale = normalizeLocale(locale)
+
+	_mu.Lock()
+	defer _mu.Unlock()
+
+	old, _ := _fallbacks.Load().(map[string][]string)
+	fm := make(map[string][]string, len(old)+1)
+	for k, v := range old {
+		fm[k] = v
+	}
+
+	list := make([]string, 0, len(fallbacks))
+	for _, f := range fallbacks {
+		list = append(list, normalizeLocale(f))
+	}
+	fm[locale] = list
+
+	_fallbacks.Store(fm)
+}
+
+// LoadCatalog load ecode messages from yaml or json file, by file extension.
+// format: {locale: {code: message}}, e.g.
+//
+//	en-US:
+//	  -50000: "server error"
+func LoadCatalog(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	catalog := make(map[string]map[int32]string)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &catalog)
+	case ".json":
+		err = json.Unmarshal(data, &catalog)
+	default:
+		return errors.Errorf("ecode: unsupported catalog file: %s", file)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "ecode: load catalog %s fail", file)
+	}
+
+	for locale, cm := range catalog {
+		RegisterLocale(locale, cm)
+	}
+
+	return nil
+}
+
+// fallbackChain expand locales by fallbacks and base language, keep order and remove duplicates.
+func fallbackChain(locales []string) []string {
+	fm, _ := _fallbacks.Load().(map[string][]string)
+
+	chain := make([]string, 0, len(locales)*2)
+	seen := make(map[string]struct{}, len(locales)*2)
+	add := func(locale string) {
+		if len(locale) <= 0 {
+			return
+		}
+		if _, ok := seen[locale]; ok {
+			return
+		}
+		seen[locale] = struct{}{}
+		chain = append(chain, locale)
+	}
+
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+		add(locale)
+		for _, f := range fm[locale] {
+			add(f)
+		}
+		if i := strings.Index(locale, "-"); i > 0 {
+			add(locale[:i])
+		}
+	}
+
+	return chain
+}
+
+func lookupLocale(code int32, locale string) (string, bool) {
+	if lm, ok := _localeMessages.Load().(map[string]map[int32]string); ok {
+		if msg, ok := lm[locale][code]; ok {
+			return msg, true
+		}
+	}
+
+	// messages passed to Register
+	if locale == normalizeLocale(DefaultLocale) {
+		if cm, ok := _messages.Load().(map[int32]string); ok {
+			msg, ok := cm[code]
+			return msg, ok
+		}
+	}
+
+	return "", false
+}
+
+// LocaleMessage return message of ec in the first matched locale,
+// falls back to ec.Message() if no locale matches.
+// message set explicitly on Status is returned as is.
+func LocaleMessage(ec Codes, locales ...string) string {
+	if s, ok := ec.(*Status); ok && len(s.message) > 0 {
+		return s.message
+	}
+
+	for _, locale := range fallbackChain(locales) {
+		if msg, ok := lookupLocale(ec.Code(), locale); ok {
+			return msg
+		}
+	}
+
+	return ec.Message()
+}
+
+// ParseAcceptLanguage parse Accept-Language header, return locales ordered by quality.
+// e.g. "en-US,en;q=0.9,zh;q=0.8" -> ["en-US", "en", "zh"]
+func ParseAcceptLanguage(al string) []string {
+	type item struct {
+		locale string
+		q      float64
+	}
+
+	items := make([]item, 0, 4)
+	for _, part := range strings.Split(al, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) <= 0 {
+			continue
+		}
+
+		it := item{locale: part, q: 1}
+		if i := strings.Index(part, ";"); i >= 0 {
+			it.locale = strings.TrimSpace(part[:i])
+			param := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					it.q = q
+				}
+			}
+		}
+
+		if it.locale == "*" || it.q <= 0 {
+			continue
+		}
+		items = append(items, it)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].q > items[j].q })
+
+	locales := make([]string, 0, len(items))
+	for _, it := range items {
+		locales = append(locales, it.locale)
+	}
+	return locales
+}
@@ -0,0 +1,71 @@
+package ecode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		al   string
+		want []string
+	}{
+		{al: "", want: []string{}},
+		{al: "zh-CN", want: []string{"zh-CN"}},
+		{al: "en-US,en;q=0.9,zh;q=0.8", want: []string{"en-US", "en", "zh"}},
+		{al: "zh;q=0.5, en-US , fr;q=0.7", want: []string{"en-US", "fr", "zh"}},
+		{al: "en;q=0.8,de;q=0.8,ja", want: []string{"ja", "en", "de"}},
+		{al: "*,en;q=0,ja;q=bad", want: []string{"ja"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.al); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q): got %v, want %v", tt.al, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackChain(t *testing.T) {
+	SetFallbacks("xx-TW", "xx-HK", "xx_CN")
+
+	got := fallbackChain([]string{"xx_TW", "en-US", "xx-HK"})
+	want := []string{"xx-tw", "xx-hk", "xx-cn", "xx", "en-us", "en"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLocaleMessage(t *testing.T) {
+	const code = 990001
+	ec := Int32(code)
+
+	Register(map[int32]string{code: "默认"})
+	RegisterLocale("yy", map[int32]string{code: "base"})
+	RegisterLocale("yy-HK", map[int32]string{code: "hk"})
+	SetFallbacks("yy-TW", "yy-HK")
+
+	tests := []struct {
+		name    string
+		locales []string
+		want    string
+	}{
+		{name: "exact", locales: ParseAcceptLanguage("yy-HK"), want: "hk"},
+		{name: "fallbacks", locales: ParseAcceptLanguage("yy-TW"), want: "hk"},
+		{name: "base language", locales: ParseAcceptLanguage("yy-US"), want: "base"},
+		{name: "by quality", locales: ParseAcceptLanguage("yy;q=0.5,yy-HK;q=0.9"), want: "hk"},
+		{name: "default locale", locales: ParseAcceptLanguage("de,zh-CN;q=0.5"), want: "默认"},
+		{name: "no locale matches", locales: ParseAcceptLanguage("de"), want: "默认"},
+		{name: "no locales", locales: nil, want: "默认"},
+	}
+
+	for _, tt := range tests {
+		if got := LocaleMessage(ec, tt.locales...); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Status 上显式设置的 message 不翻译
+	if got := LocaleMessage(Error(ec, "explicit"), "yy-HK"); got != "explicit" {
+		t.Errorf("explicit message: got %q", got)
+	}
+}
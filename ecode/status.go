@@ -0,0 +1,205 @@
+package ecode
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Status ecode with message, details and cause.
+// it implements Codes, and is immutable: every With* returns a new Status.
+type Status struct {
+	code    int32
+	message string
+	details []proto.Message
+	cause   error
+}
+
+var _ Codes = &Status{}
+
+// Error new a Status with code and message.
+// if message is empty, the registered message of code will be used.
+func Error(code Code, message string) *Status {
+	return &Status{code: code.Code(), message: message}
+}
+
+// Errorf new a Status with code and formatted message.
+func Errorf(code Code, format string, args ...interface{}) *Status {
+	return Error(code, fmt.Sprintf(format, args...))
+}
+
+// Wrap new a Status with code, and attach err as its cause.
+func Wrap(err error, code Code) *Status {
+	return &Status{code: code.Code(), cause: err}
+}
+
+// FromCodes convert Codes to Status, details are kept if it's already a Status.
+func FromCodes(ec Codes) *Status {
+	if s, ok := ec.(*Status); ok {
+		return s
+	}
+	return &Status{code: ec.Code(), message: ec.Message()}
+}
+
+func (s *Status) clone() *Status {
+	ns := *s
+	ns.details = make([]proto.Message, len(s.details), len(s.details)+1)
+	copy(ns.details, s.details)
+	return &ns
+}
+
+// Error return Code in string form, same as Code.Error.
+func (s *Status) Error() string {
+	return strconv.FormatInt(int64(s.code), 10)
+}
+
+// Code return error code
+func (s *Status) Code() int32 { return s.code }
+
+// Message return error message
+func (s *Status) Message() string {
+	if len(s.message) > 0 {
+		return s.message
+	}
+	return Int32(s.code).Message()
+}
+
+// CodeAndMessage .
+func (s *Status) CodeAndMessage() (int32, string) {
+	return s.Code(), s.Message()
+}
+
+// Details return details, every element is a proto.Message.
+func (s *Status) Details() []interface{} {
+	if len(s.details) <= 0 {
+		return nil
+	}
+
+	details := make([]interface{}, 0, len(s.details))
+	for _, d := range s.details {
+		details = append(details, d)
+	}
+	return details
+}
+
+// ProtoDetails return details as proto.Message.
+func (s *Status) ProtoDetails() []proto.Message {
+	return s.clone().details
+}
+
+// Equal for compatible.
+// Deprecated: please use ecode.EqualError.
+func (s *Status) Equal(err error) bool { return EqualError(s, err) }
+
+// Unwrap return the error wrapped by Wrap or WithCause, it may be nil.
+// NOTE: not named Cause, so errors.Cause stops at Status.
+func (s *Status) Unwrap() error { return s.cause }
+
+// Is report whether target is Codes with the same code, for errors.Is.
+func (s *Status) Is(target error) bool {
+	ec, ok := target.(Codes)
+	if !ok {
+		return false
+	}
+	return ec.Code() == s.code
+}
+
+// As support errors.As(err, &code) with *Code target.
+func (s *Status) As(target interface{}) bool {
+	if c, ok := target.(*Code); ok {
+		*c = Code(s.code)
+		return true
+	}
+	return false
+}
+
+// WithMessage return a copy with message replaced.
+func (s *Status) WithMessage(message string) *Status {
+	ns := s.clone()
+	ns.message = message
+	return ns
+}
+
+// WithCause return a copy with err attached as cause.
+func (s *Status) WithCause(err error) *Status {
+	ns := s.clone()
+	ns.cause = err
+	return ns
+}
+
+// WithDetails return a copy with details appended.
+func (s *Status) WithDetails(details ...proto.Message) *Status {
+	ns := s.clone()
+	ns.details = append(ns.details, details...)
+	return ns
+}
+
+// WithFieldViolation return a copy with a field violation appended.
+// all field violations are kept in one errdetails.BadRequest.
+func (s *Status) WithFieldViolation(field, description string) *Status {
+	ns := s.clone()
+
+	violation := &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	}
+
+	for i, d := range ns.details {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			nbr := &errdetails.BadRequest{
+				FieldViolations: append(append([]*errdetails.BadRequest_FieldViolation{}, br.FieldViolations...), violation),
+			}
+			ns.details[i] = nbr
+			return ns
+		}
+	}
+
+	ns.details = append(ns.details, &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{violation},
+	})
+	return ns
+}
+
+// WithRetryInfo return a copy telling client to retry after delay.
+func (s *Status) WithRetryInfo(delay time.Duration) *Status {
+	return s.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	})
+}
+
+// WithDebugInfo return a copy with debug info appended.
+// NOTE: debug info should not be returned to end user.
+func (s *Status) WithDebugInfo(detail string, stackEntries ...string) *Status {
+	return s.WithDetails(&errdetails.DebugInfo{
+		Detail:       detail,
+		StackEntries: stackEntries,
+	})
+}
+
+// FieldViolations return all field violations in details.
+func (s *Status) FieldViolations() []*errdetails.BadRequest_FieldViolation {
+	var list []*errdetails.BadRequest_FieldViolation
+	for _, d := range s.details {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			list = append(list, br.FieldViolations...)
+		}
+	}
+	return list
+}
+
+// RetryDelay return retry delay in details, ok is false if not exist.
+func (s *Status) RetryDelay() (time.Duration, bool) {
+	for _, d := range s.details {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			if err := ri.GetRetryDelay().CheckValid(); err != nil {
+				return 0, false
+			}
+			return ri.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
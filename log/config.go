@@ -1,9 +1,68 @@
 package log
 
+import "time"
+
 // LogConfig .
 type LogConfig struct {
 	LogPath       string `yaml:"logPath"`
 	Level         string `yaml:"level"`
 	Encoding      string `yaml:"encoding"`
 	OutputConsole bool   `yaml:"output_console"`
+
+	// 日志文件切割, 为0时使用默认值
+	MaxSize    int   `yaml:"max_size"`    // megabytes, default 100
+	MaxBackups int   `yaml:"max_backups"` // default 10
+	MaxAge     int   `yaml:"max_age"`     // days, default 10
+	Compress   *bool `yaml:"compress"`    // default true
+
+	// ErrorLogPath error 及以上级别额外写入的文件, 为空则不写
+	ErrorLogPath string `yaml:"error_log_path"`
+	// ErrorEncoding error 日志文件的编码, json or console, 默认同 Encoding
+	ErrorEncoding string `yaml:"error_encoding"`
+	// ConsoleEncoding 控制台输出的编码, json or console, 默认 console
+	ConsoleEncoding string `yaml:"console_encoding"`
+
+	// Sampling 采样, 为空则不采样
+	Sampling *SamplingConfig `yaml:"sampling"`
+	// RateLimits 按日志内容限制每秒条数, e.g. {"invoke grpc call": 100}
+	RateLimits map[string]int `yaml:"rate_limits"`
+
+	// Remote 发送到远端收集器, 为空则不发送
+	Remote *RemoteConfig `yaml:"remote"`
+
+	// Redact 日志脱敏, 为空则使用默认的 header / 字段列表
+	Redact *RedactConfig `yaml:"redact"`
+
+	// BodyLog rpc 请求/响应 body 的日志策略, 为空则完整输出
+	BodyLog *BodyLogConfig `yaml:"body_log"`
+
+	// Loggers level of named loggers, e.g. {"rpc.client": "debug"}
+	Loggers map[string]string `yaml:"loggers"`
+}
+
+// BodyLogConfig rpc 请求/响应 body 的日志策略, 见 rpc.BodyLogPolicy
+type BodyLogConfig struct {
+	Mode     string `yaml:"mode"` // on, off, truncate, error
+	MaxBytes int    `yaml:"max_bytes"`
+}
+
+// SamplingConfig 每个 tick 内, 相同级别和内容的日志, 先输出 initial 条, 之后每 thereafter 条输出一条
+type SamplingConfig struct {
+	Initial    int           `yaml:"initial"`
+	Thereafter int           `yaml:"thereafter"`
+	Tick       time.Duration `yaml:"tick"` // default 1s
+}
+
+// RemoteConfig 远端日志收集器, 为0时使用默认值
+type RemoteConfig struct {
+	Protocol string            `yaml:"protocol"` // syslog-udp, syslog-tcp, http, loki
+	Addr     string            `yaml:"addr"`     // host:port for syslog, url for http and loki
+	Tag      string            `yaml:"tag"`      // syslog app name
+	Labels   map[string]string `yaml:"labels"`   // loki stream labels
+	Timeout  time.Duration     `yaml:"timeout"`  // default 5s
+
+	BufferSize    int           `yaml:"buffer_size"`    // default 10000, 满了丢弃新日志
+	BatchSize     int           `yaml:"batch_size"`     // default 500
+	FlushInterval time.Duration `yaml:"flush_interval"` // default 1s
+	MaxRetries    int           `yaml:"max_retries"`    // default 3
 }
@@ -1,9 +1,25 @@
 package log
 
-import "context"
+import (
+	"context"
+	"sync"
+
+	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/metadata"
+)
 
 type loggerKey struct{}
 
+type fieldsKey struct{}
+
+// ContextExtractor extract log fields (key-value pairs) from ctx.
+type ContextExtractor func(ctx context.Context) []interface{}
+
+var (
+	extractorMu sync.RWMutex
+	extractors  []ContextExtractor
+)
+
 func FromContext(ctx context.Context) (Logger, bool) {
 	l, ok := ctx.Value(loggerKey{}).(Logger)
 	return l, ok
@@ -12,3 +28,64 @@ func FromContext(ctx context.Context) (Logger, bool) {
 func NewContext(ctx context.Context, l Logger) context.Context {
 	return context.WithValue(ctx, loggerKey{}, l)
 }
+
+// ContextWithFields return a ctx carrying fields, which are logged by the *Context log methods.
+// fields already in ctx are kept.
+func ContextWithFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	old, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+	nfields := make(map[string]interface{}, len(old)+len(fields))
+	for k, v := range old {
+		nfields[k] = v
+	}
+	for k, v := range fields {
+		nfields[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, nfields)
+}
+
+// RegisterContextExtractor register extractor used by ContextFields, e.g. trace id.
+func RegisterContextExtractor(e ContextExtractor) {
+	extractorMu.Lock()
+	extractors = append(extractors, e)
+	extractorMu.Unlock()
+}
+
+// ContextFields return fields (key-value pairs) carried by ctx:
+// request id and user id in rpc/metadata, fields set by ContextWithFields,
+// and fields returned by registered extractors.
+func ContextFields(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	args := make([]interface{}, 0, 6)
+	if reqID, ok := metadata.Get(ctx, rpc.RequestID); ok && len(reqID) > 0 {
+		args = append(args, "requestID", reqID)
+	}
+	if userID, ok := metadata.Get(ctx, rpc.UserID); ok && len(userID) > 0 {
+		args = append(args, "userID", userID)
+	}
+	if fields, ok := ctx.Value(fieldsKey{}).(map[string]interface{}); ok {
+		for k, v := range fields {
+			args = append(args, k, v)
+		}
+	}
+
+	extractorMu.RLock()
+	for _, e := range extractors {
+		args = append(args, e(ctx)...)
+	}
+	extractorMu.RUnlock()
+
+	return args
+}
+
+// loggerFromContext return logger in ctx, or DefaultLogger.
+func loggerFromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := FromContext(ctx); ok {
+			return l
+		}
+	}
+	return DefaultLogger
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"go.uber.org/zap"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/robert-pkg/micro-go/log"
 	zap_log "github.com/robert-pkg/micro-go/log/zap-log"
+	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/metadata"
 )
 
 func initlog() {
@@ -61,4 +64,9 @@ func main() {
 	log.Fields(map[string]interface{}{"key": "value"}).Info("xxx")
 
 	log.Debug("xxxxxx")
+
+	// 从 context 中带上 requestID 等字段, 在子 goroutine 中同样有效
+	ctx := metadata.Set(context.Background(), rpc.RequestID, "req-1")
+	ctx = log.ContextWithFields(ctx, map[string]interface{}{"order": 1})
+	log.InfoContext(ctx, "hello with context")
 }
@@ -1,61 +1,151 @@
 package log
 
-import "os"
+import (
+	"context"
+	"fmt"
+	"os"
+)
 
 type Helper struct {
 	Logger
+	ctx context.Context // bound by With, nil if not bound
 }
 
 func NewHelper(log Logger) *Helper {
 	return &Helper{Logger: log}
 }
 
+// With return a Helper bound to ctx, its Info etc. log with fields carried by ctx, see ContextFields.
+func (h *Helper) With(ctx context.Context) *Helper {
+	return &Helper{Logger: h.Logger, ctx: ctx}
+}
+
+// Fields return a child Helper with fields, bound to the same ctx as h.
+func (h *Helper) Fields(fields map[string]interface{}) *Helper {
+	nh := h.Logger.Fields(fields)
+	nh.ctx = h.ctx
+	return nh
+}
+
 func (h *Helper) Info(msg string, args ...interface{}) {
-	h.Log(InfoLevel, msg, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, InfoLevel, msg, args...)
+	} else {
+		h.Log(InfoLevel, msg, args...)
+	}
 }
 
 func (h *Helper) Infof(template string, args ...interface{}) {
-	h.Logf(InfoLevel, template, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, InfoLevel, fmt.Sprintf(template, args...))
+	} else {
+		h.Logf(InfoLevel, template, args...)
+	}
 }
 
 func (h *Helper) Trace(msg string, args ...interface{}) {
-	h.Log(TraceLevel, msg, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, TraceLevel, msg, args...)
+	} else {
+		h.Log(TraceLevel, msg, args...)
+	}
 }
 
 func (h *Helper) Tracef(template string, args ...interface{}) {
-	h.Logf(TraceLevel, template, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, TraceLevel, fmt.Sprintf(template, args...))
+	} else {
+		h.Logf(TraceLevel, template, args...)
+	}
 }
 
 func (h *Helper) Debug(msg string, args ...interface{}) {
-	h.Log(DebugLevel, msg, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, DebugLevel, msg, args...)
+	} else {
+		h.Log(DebugLevel, msg, args...)
+	}
 }
 
 func (h *Helper) Debugf(template string, args ...interface{}) {
-	h.Logf(DebugLevel, template, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, DebugLevel, fmt.Sprintf(template, args...))
+	} else {
+		h.Logf(DebugLevel, template, args...)
+	}
 }
 
 func (h *Helper) Warn(msg string, args ...interface{}) {
-	h.Log(WarnLevel, msg, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, WarnLevel, msg, args...)
+	} else {
+		h.Log(WarnLevel, msg, args...)
+	}
 }
 
 func (h *Helper) Warnf(template string, args ...interface{}) {
-	h.Logf(WarnLevel, template, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, WarnLevel, fmt.Sprintf(template, args...))
+	} else {
+		h.Logf(WarnLevel, template, args...)
+	}
 }
 
 func (h *Helper) Error(msg string, args ...interface{}) {
-	h.Log(ErrorLevel, msg, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, ErrorLevel, msg, args...)
+	} else {
+		h.Log(ErrorLevel, msg, args...)
+	}
 }
 
 func (h *Helper) Errorf(template string, args ...interface{}) {
-	h.Logf(ErrorLevel, template, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, ErrorLevel, fmt.Sprintf(template, args...))
+	} else {
+		h.Logf(ErrorLevel, template, args...)
+	}
 }
 
 func (h *Helper) Fatal(msg string, args ...interface{}) {
-	h.Log(FatalLevel, msg, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, FatalLevel, msg, args...)
+	} else {
+		h.Log(FatalLevel, msg, args...)
+	}
 	os.Exit(1)
 }
 
 func (h *Helper) Fatalf(template string, args ...interface{}) {
-	h.Logf(FatalLevel, template, args...)
+	if h.ctx != nil {
+		h.LogContext(h.ctx, FatalLevel, fmt.Sprintf(template, args...))
+	} else {
+		h.Logf(FatalLevel, template, args...)
+	}
+	os.Exit(1)
+}
+
+func (h *Helper) TraceContext(ctx context.Context, msg string, args ...interface{}) {
+	h.LogContext(ctx, TraceLevel, msg, args...)
+}
+
+func (h *Helper) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	h.LogContext(ctx, DebugLevel, msg, args...)
+}
+
+func (h *Helper) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	h.LogContext(ctx, InfoLevel, msg, args...)
+}
+
+func (h *Helper) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	h.LogContext(ctx, WarnLevel, msg, args...)
+}
+
+func (h *Helper) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	h.LogContext(ctx, ErrorLevel, msg, args...)
+}
+
+func (h *Helper) FatalContext(ctx context.Context, msg string, args ...interface{}) {
+	h.LogContext(ctx, FatalLevel, msg, args...)
 	os.Exit(1)
 }
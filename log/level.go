@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"os"
 )
@@ -116,6 +117,45 @@ func Fatalf(template string, args ...interface{}) {
 	os.Exit(1)
 }
 
+// With return a Helper bound to ctx, using logger in ctx if exist.
+//
+//	l := log.With(ctx)
+//	l.Info("handle request", "k", v) // with request id etc.
+func With(ctx context.Context) *Helper {
+	return &Helper{Logger: loggerFromContext(ctx), ctx: ctx}
+}
+
+// TraceContext log with fields carried by ctx, using logger in ctx if exist.
+func TraceContext(ctx context.Context, msg string, args ...interface{}) {
+	loggerFromContext(ctx).LogContext(ctx, TraceLevel, msg, args...)
+}
+
+// DebugContext log with fields carried by ctx, using logger in ctx if exist.
+func DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	loggerFromContext(ctx).LogContext(ctx, DebugLevel, msg, args...)
+}
+
+// InfoContext log with fields carried by ctx, using logger in ctx if exist.
+func InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	loggerFromContext(ctx).LogContext(ctx, InfoLevel, msg, args...)
+}
+
+// WarnContext log with fields carried by ctx, using logger in ctx if exist.
+func WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	loggerFromContext(ctx).LogContext(ctx, WarnLevel, msg, args...)
+}
+
+// ErrorContext log with fields carried by ctx, using logger in ctx if exist.
+func ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	loggerFromContext(ctx).LogContext(ctx, ErrorLevel, msg, args...)
+}
+
+// FatalContext log with fields carried by ctx, using logger in ctx if exist.
+func FatalContext(ctx context.Context, msg string, args ...interface{}) {
+	loggerFromContext(ctx).LogContext(ctx, FatalLevel, msg, args...)
+	os.Exit(1)
+}
+
 // Returns true if the given level is at or lower the current logger level
 func V(lvl Level, log Logger) bool {
 	l := DefaultLogger
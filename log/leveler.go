@@ -0,0 +1,129 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrLevelNotSupported DefaultLogger does not support changing level at runtime.
+var ErrLevelNotSupported = errors.New("logger does not support changing level")
+
+// Leveler logger whose level can be changed at runtime.
+// name "" is the logger itself, others are named loggers, see Named.
+// the level of "a.b" applies to "a.b.c" unless "a.b.c" has its own level.
+type Leveler interface {
+	// SetLevel set level of the named logger
+	SetLevel(name string, level Level)
+	// UnsetLevel remove the level of the named logger, it falls back to its parent
+	UnsetLevel(name string)
+	// Levels return level of logger, key "" is the logger itself
+	Levels() map[string]Level
+}
+
+// Namer logger which supports named child loggers, e.g. one per package.
+type Namer interface {
+	// Named return a child logger, names are joined by "."
+	Named(name string) Logger
+}
+
+// Named return a named child of DefaultLogger,
+// DefaultLogger itself is returned if it does not support naming.
+func Named(name string) *Helper {
+	if n, ok := DefaultLogger.(Namer); ok {
+		return NewHelper(n.Named(name))
+	}
+	return NewHelper(DefaultLogger)
+}
+
+// SetLevel set level of DefaultLogger, or of the named logger if name is not empty.
+func SetLevel(name string, level Level) error {
+	l, ok := DefaultLogger.(Leveler)
+	if !ok {
+		return ErrLevelNotSupported
+	}
+	l.SetLevel(name, level)
+	return nil
+}
+
+// UnsetLevel remove the level of the named logger of DefaultLogger.
+func UnsetLevel(name string) error {
+	l, ok := DefaultLogger.(Leveler)
+	if !ok {
+		return ErrLevelNotSupported
+	}
+	l.UnsetLevel(name)
+	return nil
+}
+
+type levelPayload struct {
+	Logger  string            `json:"logger,omitempty"`
+	Level   string            `json:"level,omitempty"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// LevelHandler http handler to read / change level of DefaultLogger at runtime.
+// mount it on an admin port only.
+//
+//	GET                                   {"level":"info","loggers":{"rpc":"debug"}}
+//	PUT {"level":"debug"}                 set level of DefaultLogger
+//	PUT {"logger":"rpc","level":"debug"}  set level of named logger
+//	DELETE ?logger=rpc                    remove level of named logger
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l, ok := DefaultLogger.(Leveler)
+		if !ok {
+			writeLevelError(w, http.StatusNotImplemented, ErrLevelNotSupported)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			level, err := GetLevel(req.Level)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			l.SetLevel(req.Logger, level)
+			Info("log level changed", "logger", req.Logger, "level", level.String())
+		case http.MethodDelete:
+			name := r.URL.Query().Get("logger")
+			if len(name) <= 0 {
+				writeLevelError(w, http.StatusBadRequest, errors.New("logger is required"))
+				return
+			}
+
+			l.UnsetLevel(name)
+			Info("log level removed", "logger", name)
+		default:
+			writeLevelError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		resp := levelPayload{Loggers: make(map[string]string)}
+		for name, level := range l.Levels() {
+			if len(name) <= 0 {
+				resp.Level = level.String()
+			} else {
+				resp.Loggers[name] = level.String()
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
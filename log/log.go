@@ -1,6 +1,8 @@
 // Package log provides a log interface
 package log
 
+import "context"
+
 var (
 	// Default logger
 	DefaultLogger Logger
@@ -18,10 +20,8 @@ type Logger interface {
 	Log(level Level, msg string, v ...interface{})
 	// Logf writes a formatted log entry
 	Logf(level Level, format string, v ...interface{})
-	// SetReqMetaForGoroutine .
-	SetReqMetaForGoroutine(requestID string)
-	// DeleteMetaForGoroutine .
-	DeleteMetaForGoroutine()
+	// LogContext writes a log entry with fields carried by ctx, see ContextFields
+	LogContext(ctx context.Context, level Level, msg string, v ...interface{})
 	// Close close log
 	Close()
 	// String returns the name of logger
@@ -36,16 +36,6 @@ func Fields(fields map[string]interface{}) *Helper {
 	return DefaultLogger.Fields(fields)
 }
 
-// SetReqMetaForGoroutine .
-func SetReqMetaForGoroutine(requestID string) {
-	DefaultLogger.SetReqMetaForGoroutine(requestID)
-}
-
-// DeleteMetaForGoroutine .
-func DeleteMetaForGoroutine() {
-	DefaultLogger.DeleteMetaForGoroutine()
-}
-
 func Close() {
 	DefaultLogger.Close()
 }
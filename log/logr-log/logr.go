@@ -0,0 +1,134 @@
+// Package logr adapts log.Logger to logr.LogSink, for libraries logging through logr,
+// e.g. otel, client-go and controller-runtime.
+package logr
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-logr/logr"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// sink adapts log.Logger to logr.LogSink.
+// V(0) is info, V(1) is debug, V(2) and above are trace.
+type sink struct {
+	logger log.Logger // nil means log.DefaultLogger at the time of logging
+	name   string     // names joined by ".", see WithName
+	values []interface{}
+	depth  int // call depth of logr.Logger and helpers of the caller
+}
+
+// NewLogSink return logr.LogSink writing to l, or to log.DefaultLogger if l is nil.
+// the caller is added as field "source", since l reports the caller of the sink.
+func NewLogSink(l log.Logger) logr.LogSink {
+	return &sink{logger: l}
+}
+
+// New return logr.Logger writing to l, or to log.DefaultLogger if l is nil.
+func New(l log.Logger) logr.Logger {
+	return logr.New(NewLogSink(l))
+}
+
+func (s *sink) getLogger() log.Logger {
+	l := s.logger
+	if l == nil {
+		l = log.DefaultLogger
+	}
+
+	if l != nil && len(s.name) > 0 {
+		if n, ok := l.(log.Namer); ok {
+			return n.Named(s.name)
+		}
+	}
+	return l
+}
+
+// Init implements logr.LogSink
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+// Enabled implements logr.LogSink
+func (s *sink) Enabled(level int) bool {
+	l := s.getLogger()
+	if l == nil {
+		return false
+	}
+	return l.Options().Level.Enabled(logrToLoggerLevel(level))
+}
+
+// Info implements logr.LogSink
+func (s *sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.log(logrToLoggerLevel(level), msg, nil, keysAndValues)
+}
+
+// Error implements logr.LogSink
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.log(log.ErrorLevel, msg, err, keysAndValues)
+}
+
+func (s *sink) log(level log.Level, msg string, err error, keysAndValues []interface{}) {
+	l := s.getLogger()
+	if l == nil {
+		return
+	}
+
+	args := make([]interface{}, 0, len(s.values)+len(keysAndValues)+4)
+	args = append(args, s.values...)
+	args = append(args, keysAndValues...)
+	if err != nil {
+		args = append(args, "err", err)
+	}
+
+	// runtime.Caller(0) is here, 1 is Info or Error, 2 is logr.Logger
+	if _, file, line, ok := runtime.Caller(2 + s.depth); ok {
+		args = append(args, "source", fmt.Sprintf("%s:%d", file, line))
+	}
+
+	l.Log(level, msg, args...)
+}
+
+// WithValues implements logr.LogSink
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	ns := *s
+	ns.values = make([]interface{}, 0, len(s.values)+len(keysAndValues))
+	ns.values = append(ns.values, s.values...)
+	ns.values = append(ns.values, keysAndValues...)
+	return &ns
+}
+
+// WithName implements logr.LogSink, the name is passed to log.Namer if supported.
+func (s *sink) WithName(name string) logr.LogSink {
+	if len(name) <= 0 {
+		return s
+	}
+
+	ns := *s
+	if len(s.name) > 0 {
+		ns.name = s.name + "." + name
+	} else {
+		ns.name = name
+	}
+	return &ns
+}
+
+// WithCallDepth implements logr.CallDepthLogSink
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	ns := *s
+	ns.depth += depth
+	return &ns
+}
+
+// logrToLoggerLevel logr levels are verbosity, bigger is more verbose
+func logrToLoggerLevel(level int) log.Level {
+	switch {
+	case level <= 0:
+		return log.InfoLevel
+	case level == 1:
+		return log.DebugLevel
+	default:
+		return log.TraceLevel
+	}
+}
@@ -0,0 +1,226 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+
+	"github.com/robert-pkg/micro-go/rpc"
+)
+
+// DefaultMask 脱敏后的值
+const DefaultMask = "******"
+
+var (
+	// DefaultRedactHeaders headers masked by default
+	DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	// DefaultRedactFields log fields and json keys masked by default
+	DefaultRedactFields = []string{"password", "passwd", "secret", "token", "access_token", "refresh_token"}
+)
+
+// Redactor hook to mask sensitive values before they are logged.
+type Redactor interface {
+	// RedactField return value to log for field key
+	RedactField(key string, value interface{}) interface{}
+	// RedactHeader return values to log for header name
+	RedactHeader(name string, values []string) []string
+	// RedactBody return json body to log, body which is not json is returned as is
+	RedactBody(body []byte) []byte
+}
+
+// RedactConfig 日志脱敏配置, 为空的列表使用默认值
+type RedactConfig struct {
+	Mask    string   `yaml:"mask"`    // default DefaultMask
+	Headers []string `yaml:"headers"` // header 名, 不区分大小写
+	Fields  []string `yaml:"fields"`  // 日志字段名及 json 中任意层级的 key, 不区分大小写
+	// BodyPaths json body 中从根开始的路径, e.g. "user.id_card", "*" 匹配任意 key, 数组会被展开
+	BodyPaths []string `yaml:"body_paths"`
+}
+
+type redactor struct {
+	mask    string
+	headers map[string]struct{}
+	fields  map[string]struct{}
+	paths   [][]string
+}
+
+var redactorValue atomic.Value // NOTE: stored redactorHolder
+
+type redactorHolder struct {
+	r Redactor
+}
+
+func init() {
+	SetRedactor(NewRedactor(nil))
+	// rpc 不能依赖 log, 由 log 注册 body 脱敏
+	rpc.SetBodyRedactor(RedactBody)
+}
+
+// NewRedactor return a Redactor masking deny-listed headers, fields and body paths.
+func NewRedactor(c *RedactConfig) Redactor {
+	if c == nil {
+		c = &RedactConfig{}
+	}
+
+	r := &redactor{
+		mask:    c.Mask,
+		headers: toLowerSet(c.Headers, DefaultRedactHeaders),
+		fields:  toLowerSet(c.Fields, DefaultRedactFields),
+	}
+	if len(r.mask) <= 0 {
+		r.mask = DefaultMask
+	}
+
+	for _, p := range c.BodyPaths {
+		p = strings.TrimPrefix(p, "$.")
+		if len(p) > 0 {
+			r.paths = append(r.paths, strings.Split(p, "."))
+		}
+	}
+
+	return r
+}
+
+func toLowerSet(list []string, defaults []string) map[string]struct{} {
+	if len(list) <= 0 {
+		list = defaults
+	}
+
+	m := make(map[string]struct{}, len(list))
+	for _, s := range list {
+		m[strings.ToLower(s)] = struct{}{}
+	}
+	return m
+}
+
+// SetRedactor replace the Redactor used by loggers, nil disables redaction.
+func SetRedactor(r Redactor) {
+	redactorValue.Store(redactorHolder{r: r})
+}
+
+// GetRedactor return the current Redactor, nil if redaction is disabled.
+func GetRedactor() Redactor {
+	return redactorValue.Load().(redactorHolder).r
+}
+
+// RedactField .
+func RedactField(key string, value interface{}) interface{} {
+	if r := GetRedactor(); r != nil {
+		return r.RedactField(key, value)
+	}
+	return value
+}
+
+// RedactHeader .
+func RedactHeader(name string, values []string) []string {
+	if r := GetRedactor(); r != nil {
+		return r.RedactHeader(name, values)
+	}
+	return values
+}
+
+// RedactBody .
+func RedactBody(body []byte) []byte {
+	if r := GetRedactor(); r != nil {
+		return r.RedactBody(body)
+	}
+	return body
+}
+
+func (r *redactor) RedactField(key string, value interface{}) interface{} {
+	if _, ok := r.fields[strings.ToLower(key)]; ok {
+		return r.mask
+	}
+	return value
+}
+
+func (r *redactor) RedactHeader(name string, values []string) []string {
+	if _, ok := r.headers[strings.ToLower(name)]; !ok {
+		return values
+	}
+
+	masked := make([]string, len(values))
+	for i := range masked {
+		masked[i] = r.mask
+	}
+	return masked
+}
+
+func (r *redactor) RedactBody(body []byte) []byte {
+	if len(body) <= 0 || (len(r.fields) <= 0 && len(r.paths) <= 0) {
+		return body
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+
+	changed := r.maskFields(v)
+	for _, path := range r.paths {
+		if r.maskPath(v, path) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return body
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// maskFields mask deny-listed keys at any depth
+func (r *redactor) maskFields(v interface{}) (changed bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if _, ok := r.fields[strings.ToLower(k)]; ok {
+				t[k] = r.mask
+				changed = true
+			} else if r.maskFields(child) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if r.maskFields(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// maskPath mask values at path, arrays on the way are expanded
+func (r *redactor) maskPath(v interface{}, path []string) (changed bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if path[0] != "*" && path[0] != k {
+				continue
+			}
+
+			if len(path) == 1 {
+				t[k] = r.mask
+				changed = true
+			} else if r.maskPath(child, path[1:]) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if r.maskPath(child, path) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,105 @@
+package log
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedactField(t *testing.T) {
+	r := NewRedactor(nil)
+
+	tests := []struct {
+		key   string
+		value interface{}
+		want  interface{}
+	}{
+		{key: "password", value: "p", want: DefaultMask},
+		{key: "Access_Token", value: "t", want: DefaultMask},
+		{key: "user", value: "u", want: "u"},
+		{key: "id", value: 1, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := r.RedactField(tt.key, tt.value); got != tt.want {
+			t.Errorf("RedactField(%q): got %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	r := NewRedactor(&RedactConfig{Mask: "x", Headers: []string{"X-Sign"}})
+
+	if got := r.RedactHeader("x-sign", []string{"a", "b"}); !reflect.DeepEqual(got, []string{"x", "x"}) {
+		t.Errorf("configured header: %v", got)
+	}
+	// 配置了 Headers 时不再使用默认值
+	if got := r.RedactHeader("Authorization", []string{"a"}); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("default header: %v", got)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *RedactConfig
+		body string
+		want string
+	}{
+		{
+			name: "field at any depth",
+			body: `{"user":{"name":"a","Password":"p"},"list":[{"token":"t"}]}`,
+			want: `{"list":[{"token":"******"}],"user":{"Password":"******","name":"a"}}`,
+		},
+		{
+			name: "path",
+			c:    &RedactConfig{BodyPaths: []string{"$.user.id_card", "items.*.phone"}},
+			body: `{"user":{"id_card":"1","name":"a"},"items":[{"a":{"phone":"1"}},{"b":{"phone":"2"}}],"id_card":"2"}`,
+			want: `{"id_card":"2","items":[{"a":{"phone":"******"}},{"b":{"phone":"******"}}],"user":{"id_card":"******","name":"a"}}`,
+		},
+		{
+			name: "number kept",
+			body: `{"id":12345678901234567890,"secret":1}`,
+			want: `{"id":12345678901234567890,"secret":"******"}`,
+		},
+		{
+			name: "not changed",
+			body: `{"name": "a"}`,
+			want: `{"name": "a"}`,
+		},
+		{
+			name: "not json",
+			body: `password=p`,
+			want: `password=p`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(NewRedactor(tt.c).RedactBody([]byte(tt.body)))
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if tt.body != tt.want && !json.Valid([]byte(got)) {
+				t.Errorf("invalid json: %s", got)
+			}
+		})
+	}
+}
+
+func TestSetRedactor(t *testing.T) {
+	defer SetRedactor(NewRedactor(nil))
+
+	SetRedactor(nil)
+	if got := RedactField("password", "p"); got != "p" {
+		t.Errorf("disabled: got %v", got)
+	}
+	if got := string(RedactBody([]byte(`{"token":"t"}`))); got != `{"token":"t"}` {
+		t.Errorf("disabled: got %s", got)
+	}
+
+	SetRedactor(NewRedactor(&RedactConfig{Mask: "-"}))
+	if got := RedactField("password", "p"); got != "-" {
+		t.Errorf("mask: got %v", got)
+	}
+}
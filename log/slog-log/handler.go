@@ -0,0 +1,108 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// handler adapts log.Logger to slog.Handler
+type handler struct {
+	logger log.Logger // nil means log.DefaultLogger at the time of logging
+	attrs  []interface{}
+	group  string // prefix of keys, e.g. "g1.g2."
+}
+
+// NewHandler return slog.Handler writing to l, or to log.DefaultLogger if l is nil,
+// so that slog.New(NewHandler(nil)) writes to the same sinks with the same request id fields.
+// the caller is added as field "source", since l reports the caller of the handler.
+func NewHandler(l log.Logger) slog.Handler {
+	return &handler{logger: l}
+}
+
+func (h *handler) getLogger() log.Logger {
+	if h.logger != nil {
+		return h.logger
+	}
+	return log.DefaultLogger
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	l := h.getLogger()
+	if l == nil {
+		return false
+	}
+	return l.Options().Level.Enabled(slogToLoggerLevel(level))
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.getLogger()
+	if l == nil {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(h.attrs)+2*r.NumAttrs()+2)
+	args = append(args, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		args = appendAttr(args, h.group, a)
+		return true
+	})
+
+	if r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		f, _ := frames.Next()
+		args = append(args, "source", fmt.Sprintf("%s:%d", f.File, f.Line))
+	}
+
+	level := slogToLoggerLevel(r.Level)
+	if level == log.FatalLevel {
+		// slog 调用方不期望退出进程
+		level = log.ErrorLevel
+	}
+
+	l.LogContext(ctx, level, r.Message, args...)
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.attrs = make([]interface{}, 0, len(h.attrs)+2*len(attrs))
+	nh.attrs = append(nh.attrs, h.attrs...)
+	for _, a := range attrs {
+		nh.attrs = appendAttr(nh.attrs, h.group, a)
+	}
+	return &nh
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if len(name) <= 0 {
+		return h
+	}
+
+	nh := *h
+	nh.group = h.group + name + "."
+	return &nh
+}
+
+// appendAttr append attr as key-value pairs, groups are flattened to "group.key"
+func appendAttr(args []interface{}, prefix string, a slog.Attr) []interface{} {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if len(a.Key) > 0 {
+			prefix = prefix + a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			args = appendAttr(args, prefix, ga)
+		}
+		return args
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return args
+	}
+
+	return append(args, prefix+a.Key, v.Any())
+}
@@ -0,0 +1,28 @@
+package slog
+
+import (
+	"log/slog"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+type handlerKey struct{}
+
+// WithHandler write logs to h, default is a text handler writing to LogFileName or console
+func WithHandler(h slog.Handler) log.Option {
+	return log.SetOption(handlerKey{}, h)
+}
+
+type callerSkipKey struct{}
+
+// WithCallerSkip same as zap-log, 1(default) is the caller of Logger.Log, use 2 if it's DefaultLogger
+func WithCallerSkip(i int) log.Option {
+	return log.SetOption(callerSkipKey{}, i)
+}
+
+type jsonKey struct{}
+
+// WithJSON use json handler instead of text handler, ignored if WithHandler is set
+func WithJSON(json bool) log.Option {
+	return log.SetOption(jsonKey{}, json)
+}
@@ -0,0 +1,248 @@
+// Package slog implements log.Logger on top of log/slog,
+// and adapts log.Logger to slog.Handler, see NewHandler.
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// LevelTrace LevelFatal slog has no trace and fatal level
+const (
+	LevelTrace = slog.LevelDebug - 4
+	LevelFatal = slog.LevelError + 4
+)
+
+type slogLogger struct {
+	handler slog.Handler
+	level   *slog.LevelVar // shared with loggers derived from this one
+	skip    int
+	opts    log.Options
+}
+
+func (l *slogLogger) Init(opts ...log.Option) error {
+	for _, o := range opts {
+		o(&l.opts)
+	}
+
+	if l.level == nil {
+		l.level = new(slog.LevelVar)
+	}
+	l.level.Set(loggerToSlogLevel(l.opts.Level))
+
+	skip, ok := l.opts.Context.Value(callerSkipKey{}).(int)
+	if !ok || skip < 1 {
+		skip = 1
+	}
+	l.skip = skip
+
+	h, ok := l.opts.Context.Value(handlerKey{}).(slog.Handler)
+	if !ok || h == nil {
+		var w io.Writer = os.Stdout
+		if len(l.opts.LogFileName) > 0 {
+			w = &lumberjack.Logger{
+				Filename:   l.opts.LogFileName,
+				MaxSize:    100, // megabytes
+				MaxBackups: 10,
+				MaxAge:     10, // days
+				LocalTime:  true,
+				Compress:   true,
+			}
+		}
+
+		hOpts := &slog.HandlerOptions{
+			AddSource:   true,
+			Level:       LevelTrace, // level is checked by slogLogger
+			ReplaceAttr: replaceLevel,
+		}
+		if json, _ := l.opts.Context.Value(jsonKey{}).(bool); json {
+			h = slog.NewJSONHandler(w, hOpts)
+		} else {
+			h = slog.NewTextHandler(w, hOpts)
+		}
+	}
+
+	// Adding seed fields if exist
+	if len(l.opts.Fields) > 0 {
+		h = h.WithAttrs(fieldsToAttrs(l.opts.Fields))
+	}
+
+	l.handler = h
+	return nil
+}
+
+// replaceLevel print TRACE and FATAL instead of DEBUG-4 and ERROR+4
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.LevelKey || len(groups) > 0 {
+		return a
+	}
+
+	switch a.Value.Any() {
+	case LevelTrace:
+		a.Value = slog.StringValue("TRACE")
+	case LevelFatal:
+		a.Value = slog.StringValue("FATAL")
+	}
+	return a
+}
+
+func fieldsToAttrs(fields map[string]interface{}) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for k, v := range fields {
+		attrs = append(attrs, slog.Any(k, log.RedactField(k, v)))
+	}
+	return attrs
+}
+
+func (l *slogLogger) Options() log.Options {
+	opts := l.opts
+	// 级别可能在运行时被修改
+	opts.Level = slogToLoggerLevel(l.level.Level())
+	return opts
+}
+
+func (l *slogLogger) Fields(fields map[string]interface{}) *log.Helper {
+	nl := *l
+	nl.handler = l.handler.WithAttrs(fieldsToAttrs(fields))
+	return log.NewHelper(&nl)
+}
+
+func (l *slogLogger) Log(level log.Level, msg string, args ...interface{}) {
+	l.write(context.Background(), level, msg, args, nil)
+}
+
+func (l *slogLogger) Logf(level log.Level, format string, args ...interface{}) {
+	l.write(context.Background(), level, fmt.Sprintf(format, args...), nil, nil)
+}
+
+func (l *slogLogger) LogContext(ctx context.Context, level log.Level, msg string, args ...interface{}) {
+	l.write(ctx, level, msg, args, log.ContextFields(ctx))
+}
+
+func (l *slogLogger) write(ctx context.Context, level log.Level, msg string, args []interface{}, ctxArgs []interface{}) {
+	lvl := loggerToSlogLevel(level)
+	if lvl < l.level.Level() || !l.handler.Enabled(ctx, lvl) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(l.skip+2, pcs[:]) // +2 for runtime.Callers and write
+
+	r := slog.NewRecord(time.Now(), lvl, msg, pcs[0])
+	r.AddAttrs(argsToAttrs(args)...)
+	r.AddAttrs(argsToAttrs(ctxArgs)...)
+	_ = l.handler.Handle(ctx, r)
+
+	if level == log.FatalLevel {
+		os.Exit(1)
+	}
+}
+
+// argsToAttrs key-value pairs to attrs, the same as zap-log
+func argsToAttrs(args []interface{}) []slog.Attr {
+	var sz = len(args)
+	attrs := make([]slog.Attr, 0, (sz+1)/2)
+	for i := 0; i < sz; i += 2 {
+		k, ok := args[i].(string)
+		var v interface{}
+		if !ok {
+			k, v = "ErrorKey", args[i]
+		}
+
+		if (i + 1) < sz {
+			v = args[i+1]
+		}
+
+		attrs = append(attrs, slog.Any(k, log.RedactField(k, v)))
+	}
+	return attrs
+}
+
+func (l *slogLogger) Close() {
+}
+
+func (l *slogLogger) String() string {
+	return "slog"
+}
+
+// SetLevel implements log.Leveler, only the logger itself ("") is supported.
+func (l *slogLogger) SetLevel(name string, level log.Level) {
+	if len(name) <= 0 {
+		l.level.Set(loggerToSlogLevel(level))
+	}
+}
+
+// UnsetLevel implements log.Leveler.
+func (l *slogLogger) UnsetLevel(name string) {
+}
+
+// Levels implements log.Leveler.
+func (l *slogLogger) Levels() map[string]log.Level {
+	return map[string]log.Level{"": slogToLoggerLevel(l.level.Level())}
+}
+
+// NewLogger builds a new logger based on options
+func NewLogger(asDefault bool, opts ...log.Option) (log.Logger, error) {
+	// Default options
+	options := log.Options{
+		Level:   log.InfoLevel,
+		Fields:  make(map[string]interface{}),
+		Context: context.Background(),
+	}
+
+	l := &slogLogger{opts: options}
+	if err := l.Init(opts...); err != nil {
+		return nil, err
+	}
+
+	if asDefault {
+		log.DefaultLogger = l
+	}
+
+	return l, nil
+}
+
+func loggerToSlogLevel(level log.Level) slog.Level {
+	switch level {
+	case log.TraceLevel:
+		return LevelTrace
+	case log.DebugLevel:
+		return slog.LevelDebug
+	case log.InfoLevel:
+		return slog.LevelInfo
+	case log.WarnLevel:
+		return slog.LevelWarn
+	case log.ErrorLevel:
+		return slog.LevelError
+	case log.FatalLevel:
+		return LevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func slogToLoggerLevel(level slog.Level) log.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return log.TraceLevel
+	case level < slog.LevelInfo:
+		return log.DebugLevel
+	case level < slog.LevelWarn:
+		return log.InfoLevel
+	case level < slog.LevelError:
+		return log.WarnLevel
+	case level < LevelFatal:
+		return log.ErrorLevel
+	default:
+		return log.FatalLevel
+	}
+}
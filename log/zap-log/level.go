@@ -0,0 +1,152 @@
+package zap
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// levels level of root logger and overrides of named loggers,
+// shared by all loggers derived from the same root.
+type levels struct {
+	sync.Mutex
+	global    zap.AtomicLevel
+	overrides atomic.Value // NOTE: stored *overrideSet
+}
+
+type overrideSet struct {
+	m   map[string]zapcore.Level
+	min zapcore.Level // lowest level in m
+}
+
+func newLevels(global zap.AtomicLevel) *levels {
+	lv := &levels{global: global}
+	lv.overrides.Store(&overrideSet{m: map[string]zapcore.Level{}})
+	return lv
+}
+
+// lookup return level of the named logger.
+// "a.b.c" falls back to "a.b", then "a", then root level.
+func (lv *levels) lookup(name string) zapcore.Level {
+	set := lv.overrides.Load().(*overrideSet)
+	for len(set.m) > 0 && len(name) > 0 {
+		if lvl, ok := set.m[name]; ok {
+			return lvl
+		}
+
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return lv.global.Level()
+}
+
+func (lv *levels) enabled(name string, lvl zapcore.Level) bool {
+	return lvl >= lv.lookup(name)
+}
+
+// Enabled implements zapcore.LevelEnabler, used by cores.
+// it lets through the lowest level of all loggers, the real check is done by enabled.
+func (lv *levels) Enabled(lvl zapcore.Level) bool {
+	set := lv.overrides.Load().(*overrideSet)
+	if len(set.m) > 0 && lvl >= set.min {
+		return true
+	}
+	return lv.global.Enabled(lvl)
+}
+
+func (lv *levels) set(name string, lvl zapcore.Level) {
+	if len(name) <= 0 {
+		lv.global.SetLevel(lvl)
+		return
+	}
+
+	lv.Lock()
+	defer lv.Unlock()
+
+	old := lv.overrides.Load().(*overrideSet)
+	m := make(map[string]zapcore.Level, len(old.m)+1)
+	for k, v := range old.m {
+		m[k] = v
+	}
+	m[name] = lvl
+	lv.overrides.Store(newOverrideSet(m))
+}
+
+func (lv *levels) unset(name string) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	old := lv.overrides.Load().(*overrideSet)
+	m := make(map[string]zapcore.Level, len(old.m))
+	for k, v := range old.m {
+		if k != name {
+			m[k] = v
+		}
+	}
+	lv.overrides.Store(newOverrideSet(m))
+}
+
+// reset replace all overrides.
+func (lv *levels) reset(m map[string]zapcore.Level) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	nm := make(map[string]zapcore.Level, len(m))
+	for k, v := range m {
+		nm[k] = v
+	}
+	lv.overrides.Store(newOverrideSet(nm))
+}
+
+func newOverrideSet(m map[string]zapcore.Level) *overrideSet {
+	set := &overrideSet{m: m, min: zapcore.FatalLevel}
+	for _, lvl := range m {
+		if lvl < set.min {
+			set.min = lvl
+		}
+	}
+	return set
+}
+
+// SetLevel implements log.Leveler
+func (l *zaplog) SetLevel(name string, level log.Level) {
+	l.levels.set(name, loggerToZapLevel(level))
+}
+
+// UnsetLevel implements log.Leveler
+func (l *zaplog) UnsetLevel(name string) {
+	l.levels.unset(name)
+}
+
+// Levels implements log.Leveler
+func (l *zaplog) Levels() map[string]log.Level {
+	set := l.levels.overrides.Load().(*overrideSet)
+	result := make(map[string]log.Level, len(set.m)+1)
+	result[""] = zapToLoggerLevel(l.levels.global.Level())
+	for k, v := range set.m {
+		result[k] = zapToLoggerLevel(v)
+	}
+	return result
+}
+
+// Named implements log.Namer
+func (l *zaplog) Named(name string) log.Logger {
+	fullName := name
+	if len(l.name) > 0 {
+		fullName = l.name + "." + name
+	}
+
+	l.RLock()
+	fields := l.fields // never modified after created
+	l.RUnlock()
+
+	return l.derive(l.base.Named(name), fullName, fields)
+}
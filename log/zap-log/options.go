@@ -1,6 +1,8 @@
 package zap
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -36,3 +38,69 @@ type namespaceKey struct{}
 func WithNamespace(namespace string) log.Option {
 	return log.SetOption(namespaceKey{}, namespace)
 }
+
+// Rotate rotation settings of log files
+type Rotate struct {
+	MaxSize    int  // megabytes
+	MaxBackups int  // number of old files to retain
+	MaxAge     int  // days
+	Compress   bool // gzip old files
+}
+
+// DefaultRotate .
+var DefaultRotate = Rotate{
+	MaxSize:    100,
+	MaxBackups: 10,
+	MaxAge:     10,
+	Compress:   true,
+}
+
+type rotateKey struct{}
+
+// WithRotate set rotation settings of log files, default is DefaultRotate
+func WithRotate(r Rotate) log.Option {
+	return log.SetOption(rotateKey{}, r)
+}
+
+type errorLogFileNameKey struct{}
+
+// WithErrorLogFileName write error and above level logs to a separate file as well
+func WithErrorLogFileName(name string) log.Option {
+	return log.SetOption(errorLogFileNameKey{}, name)
+}
+
+type consoleEncodingKey struct{}
+
+// WithConsoleEncoding set encoding of console sink, "json" or "console"(default)
+func WithConsoleEncoding(encoding string) log.Option {
+	return log.SetOption(consoleEncodingKey{}, encoding)
+}
+
+type errorEncodingKey struct{}
+
+// WithErrorEncoding set encoding of error log file, default is the same as zap.Config.Encoding
+func WithErrorEncoding(encoding string) log.Option {
+	return log.SetOption(errorEncodingKey{}, encoding)
+}
+
+// Sampling log the first Initial entries with the same level and message in each Tick,
+// then every Thereafter-th entry.
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+type samplingKey struct{}
+
+// WithSampling enable sampling of all sinks
+func WithSampling(s Sampling) log.Option {
+	return log.SetOption(samplingKey{}, s)
+}
+
+type rateLimitsKey struct{}
+
+// WithRateLimits limit entries per second of messages, e.g. {"invoke grpc call": 100}
+func WithRateLimits(limits map[string]int) log.Option {
+	return log.SetOption(rateLimitsKey{}, limits)
+}
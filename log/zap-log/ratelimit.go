@@ -0,0 +1,95 @@
+package zap
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// rateLimitCore limit entries per second of configured messages,
+// entries over the limit are dropped and counted.
+type rateLimitCore struct {
+	zapcore.Core
+	limiters map[string]*msgLimiter // read only after created
+}
+
+type msgLimiter struct {
+	sync.Mutex
+	limit   int64
+	second  int64
+	count   int64
+	dropped int64 // atomic
+}
+
+func newRateLimitCore(core zapcore.Core, limits map[string]int) *rateLimitCore {
+	limiters := make(map[string]*msgLimiter, len(limits))
+	for msg, limit := range limits {
+		if limit > 0 {
+			limiters[msg] = &msgLimiter{limit: int64(limit)}
+		}
+	}
+
+	if len(limiters) <= 0 {
+		return nil
+	}
+
+	return &rateLimitCore{Core: core, limiters: limiters}
+}
+
+func (l *msgLimiter) allow(now time.Time) bool {
+	sec := now.Unix()
+
+	l.Lock()
+	defer l.Unlock()
+
+	if sec != l.second {
+		l.second = sec
+		l.count = 0
+	}
+
+	if l.count >= l.limit {
+		atomic.AddInt64(&l.dropped, 1)
+		return false
+	}
+
+	l.count++
+	return true
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiters: c.limiters}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	if limiter, ok := c.limiters[ent.Message]; ok && !limiter.allow(ent.Time) {
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// dropped return count of dropped entries of each limited message.
+func (c *rateLimitCore) dropped() map[string]int64 {
+	result := make(map[string]int64, len(c.limiters))
+	for msg, limiter := range c.limiters {
+		result[msg] = atomic.LoadInt64(&limiter.dropped)
+	}
+	return result
+}
+
+// RateLimitDropped return count of entries dropped by rate limits of DefaultLogger, see WithRateLimits.
+func RateLimitDropped() map[string]int64 {
+	l, ok := log.DefaultLogger.(*zaplog)
+	if !ok || l.rateLimit == nil {
+		return nil
+	}
+	return l.rateLimit.dropped()
+}
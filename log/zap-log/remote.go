@@ -0,0 +1,497 @@
+package zap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// remote protocols
+const (
+	RemoteSyslogUDP = "syslog-udp"
+	RemoteSyslogTCP = "syslog-tcp"
+	RemoteHTTP      = "http" // POST json array of entries
+	RemoteLoki      = "loki" // loki push api, e.g. http://loki:3100/loki/api/v1/push
+)
+
+// Remote settings of shipping logs to a remote collector
+type Remote struct {
+	Protocol string
+	Addr     string            // host:port for syslog, url for http and loki
+	Tag      string            // syslog app name, default is program name
+	Labels   map[string]string // loki stream labels
+	Timeout  time.Duration     // timeout of a request, and max wait of Sync and Close, default 5s
+
+	BufferSize    int           // entries buffered in memory, new entries are dropped when full, default 10000
+	BatchSize     int           // max entries per request, default 500
+	FlushInterval time.Duration // default 1s
+	MaxRetries    int           // retries of a failed batch, then it's dropped, default 3
+}
+
+// RemoteStats counters of remote shipping
+type RemoteStats struct {
+	Sent    uint64 // entries sent
+	Dropped uint64 // entries dropped because buffer is full
+	Failed  uint64 // entries dropped because send failed
+}
+
+type remoteEntry struct {
+	level zapcore.Level
+	time  time.Time
+	line  []byte // json encoded entry, without line ending
+}
+
+// remoteSink buffer entries and send them in batches by a background goroutine
+type remoteSink struct {
+	r      Remote
+	send   func(batch []remoteEntry, deadline time.Time) error
+	client *http.Client
+	conn   net.Conn // syslog only, reconnect when nil
+	host   string
+
+	entries chan remoteEntry
+	flushCh chan flushReq
+
+	closeOnce     sync.Once
+	closed        int32         // atomic, new entries are dropped after closed
+	closeDeadline time.Time     // set before stopCh is closed
+	stopCh        chan struct{} // closed by close
+	doneCh        chan struct{} // closed when run exits
+
+	sent    uint64 // atomic
+	dropped uint64 // atomic
+	failed  uint64 // atomic
+}
+
+// flushReq send buffered entries before deadline, done is closed after sent
+type flushReq struct {
+	deadline time.Time
+	done     chan struct{}
+}
+
+type remoteKey struct{}
+
+// WithRemote ship json entries to a remote collector, in addition to file and console
+func WithRemote(r Remote) log.Option {
+	return log.SetOption(remoteKey{}, r)
+}
+
+func newRemoteSink(r Remote) (*remoteSink, error) {
+	if len(r.Addr) <= 0 {
+		return nil, fmt.Errorf("remote log addr is required")
+	}
+	if r.Timeout <= 0 {
+		r.Timeout = 5 * time.Second
+	}
+	if r.BufferSize <= 0 {
+		r.BufferSize = 10000
+	}
+	if r.BatchSize <= 0 {
+		r.BatchSize = 500
+	}
+	if r.FlushInterval <= 0 {
+		r.FlushInterval = time.Second
+	}
+	if r.MaxRetries <= 0 {
+		r.MaxRetries = 3
+	}
+	if len(r.Tag) <= 0 && len(os.Args) > 0 {
+		r.Tag = os.Args[0]
+	}
+
+	s := &remoteSink{
+		r:       r,
+		client:  &http.Client{},
+		entries: make(chan remoteEntry, r.BufferSize),
+		flushCh: make(chan flushReq),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	s.host, _ = os.Hostname()
+
+	switch r.Protocol {
+	case RemoteSyslogUDP, RemoteSyslogTCP:
+		s.send = s.sendSyslog
+	case RemoteHTTP:
+		s.send = s.sendHTTP
+	case RemoteLoki:
+		s.send = s.sendLoki
+	default:
+		return nil, fmt.Errorf("unknown remote log protocol: '%s'", r.Protocol)
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// push never blocks, the entry is dropped if buffer is full or sink is closed
+func (s *remoteSink) push(e remoteEntry) {
+	if atomic.LoadInt32(&s.closed) != 0 {
+		atomic.AddUint64(&s.dropped, 1)
+		return
+	}
+
+	select {
+	case s.entries <- e:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// flush send buffered entries and wait at most timeout, entries not sent before timeout are dropped
+func (s *remoteSink) flush(timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	req := flushReq{deadline: time.Now().Add(timeout), done: make(chan struct{})}
+	select {
+	case s.flushCh <- req:
+	case <-s.doneCh:
+		return
+	case <-timer.C:
+		return
+	}
+
+	select {
+	case <-req.done:
+	case <-timer.C:
+	}
+}
+
+// close send buffered entries before timeout, then stop the background goroutine and close the connection.
+// it returns after at most timeout, entries pushed after close are dropped.
+func (s *remoteSink) close(timeout time.Duration) {
+	s.closeOnce.Do(func() {
+		atomic.StoreInt32(&s.closed, 1)
+		s.closeDeadline = time.Now().Add(timeout)
+		close(s.stopCh)
+	})
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-s.doneCh:
+	case <-timer.C:
+	}
+}
+
+func (s *remoteSink) stats() RemoteStats {
+	return RemoteStats{
+		Sent:    atomic.LoadUint64(&s.sent),
+		Dropped: atomic.LoadUint64(&s.dropped),
+		Failed:  atomic.LoadUint64(&s.failed),
+	}
+}
+
+func (s *remoteSink) run() {
+	ticker := time.NewTicker(s.r.FlushInterval)
+	defer ticker.Stop()
+
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+			s.conn = nil
+		}
+		s.client.CloseIdleConnections()
+		close(s.doneCh)
+	}()
+
+	batch := make([]remoteEntry, 0, s.r.BatchSize)
+	for {
+		select {
+		case e := <-s.entries:
+			batch = append(batch, e)
+			if len(batch) >= s.r.BatchSize {
+				batch = s.sendBatch(batch, time.Time{})
+			}
+		case <-ticker.C:
+			batch = s.sendBatch(batch, time.Time{})
+		case req := <-s.flushCh:
+			batch = s.drain(batch, req.deadline)
+			close(req.done)
+		case <-s.stopCh:
+			s.drain(batch, s.closeDeadline)
+			return
+		}
+	}
+}
+
+// drain send batch and entries in buffer before deadline
+func (s *remoteSink) drain(batch []remoteEntry, deadline time.Time) []remoteEntry {
+	for n := len(s.entries); n > 0; n-- {
+		batch = append(batch, <-s.entries)
+		if len(batch) >= s.r.BatchSize {
+			batch = s.sendBatch(batch, deadline)
+		}
+	}
+	return s.sendBatch(batch, deadline)
+}
+
+// errRemoteDeadline batch is dropped because the deadline of flush or close is exceeded
+var errRemoteDeadline = errors.New("deadline exceeded")
+
+// sendBatch send with retries before deadline, zero deadline means no limit except retries.
+// return batch emptied for reuse.
+func (s *remoteSink) sendBatch(batch []remoteEntry, deadline time.Time) []remoteEntry {
+	if len(batch) <= 0 {
+		return batch
+	}
+
+	var err error
+	for i := 0; i <= s.r.MaxRetries; i++ {
+		d := s.sendDeadline(deadline)
+		if i > 0 {
+			backoff := time.Duration(i) * 100 * time.Millisecond
+			if !d.IsZero() && time.Now().Add(backoff).After(d) {
+				break
+			}
+			time.Sleep(backoff)
+		}
+
+		// 每次发送最多 Timeout, 且不超过 deadline
+		attempt := time.Now().Add(s.r.Timeout)
+		if !d.IsZero() && d.Before(attempt) {
+			attempt = d
+		}
+		if !time.Now().Before(attempt) {
+			if err == nil {
+				err = errRemoteDeadline
+			}
+			break
+		}
+
+		if err = s.send(batch, attempt); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		atomic.AddUint64(&s.failed, uint64(len(batch)))
+		// 不能写日志, 否则会再次进入 remote
+		fmt.Fprintf(os.Stderr, "send logs to %s fail, %d entries dropped: %v\n", s.r.Addr, len(batch), err)
+	} else {
+		atomic.AddUint64(&s.sent, uint64(len(batch)))
+	}
+
+	for i := range batch {
+		batch[i] = remoteEntry{}
+	}
+	return batch[:0]
+}
+
+// closeIfNot close s with its Timeout unless it's cur, nil s is ok
+func (s *remoteSink) closeIfNot(cur *remoteSink) {
+	if s != nil && s != cur {
+		s.close(s.r.Timeout)
+	}
+}
+
+// sendDeadline the earlier of deadline and the deadline of close, if closed
+func (s *remoteSink) sendDeadline(deadline time.Time) time.Time {
+	select {
+	case <-s.stopCh:
+		if deadline.IsZero() || s.closeDeadline.Before(deadline) {
+			return s.closeDeadline
+		}
+	default:
+	}
+	return deadline
+}
+
+// sendSyslog RFC 5424 messages, octet-counting framing over tcp (RFC 6587), one datagram per entry over udp
+func (s *remoteSink) sendSyslog(batch []remoteEntry, deadline time.Time) error {
+	if s.conn == nil {
+		network := "udp"
+		if s.r.Protocol == RemoteSyslogTCP {
+			network = "tcp"
+		}
+
+		conn, err := net.DialTimeout(network, s.r.Addr, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	var buf bytes.Buffer
+	for _, e := range batch {
+		msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+			syslogPriority(e.level), e.time.Format(time.RFC3339Nano), s.host, s.r.Tag, os.Getpid(), e.line)
+
+		if s.r.Protocol == RemoteSyslogTCP {
+			buf.WriteString(strconv.Itoa(len(msg)))
+			buf.WriteByte(' ')
+			buf.WriteString(msg)
+			continue
+		}
+
+		s.conn.SetWriteDeadline(deadline)
+		if _, err := s.conn.Write([]byte(msg)); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+
+	if buf.Len() > 0 {
+		s.conn.SetWriteDeadline(deadline)
+		if _, err := s.conn.Write(buf.Bytes()); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// syslogPriority facility user(1)
+func syslogPriority(level zapcore.Level) int {
+	severity := 6 // informational
+	switch {
+	case level < zapcore.InfoLevel:
+		severity = 7 // debug
+	case level == zapcore.WarnLevel:
+		severity = 4 // warning
+	case level == zapcore.ErrorLevel:
+		severity = 3 // error
+	case level > zapcore.ErrorLevel:
+		severity = 2 // critical
+	}
+	return 1*8 + severity
+}
+
+func (s *remoteSink) sendHTTP(batch []remoteEntry, deadline time.Time) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, e := range batch {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(e.line)
+	}
+	buf.WriteByte(']')
+
+	return s.post(buf.Bytes(), deadline)
+}
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *remoteSink) sendLoki(batch []remoteEntry, deadline time.Time) error {
+	stream := lokiStream{Stream: s.r.Labels, Values: make([][2]string, 0, len(batch))}
+	if len(stream.Stream) <= 0 {
+		stream.Stream = map[string]string{"job": s.r.Tag}
+	}
+
+	for _, e := range batch {
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), string(e.line)})
+	}
+
+	body, err := json.Marshal(lokiPush{Streams: []lokiStream{stream}})
+	if err != nil {
+		return err
+	}
+	return s.post(body, deadline)
+}
+
+func (s *remoteSink) post(body []byte, deadline time.Time) error {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.r.Addr, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// remoteCore encode entries as json and push them to sink
+type remoteCore struct {
+	zapcore.LevelEnabler
+	enc  zapcore.Encoder
+	sink *remoteSink
+}
+
+func newRemoteCore(cfg zapcore.EncoderConfig, sink *remoteSink, enab zapcore.LevelEnabler) *remoteCore {
+	cfg.LineEnding = ""
+	return &remoteCore{LevelEnabler: enab, enc: zapcore.NewJSONEncoder(cfg), sink: sink}
+}
+
+func (c *remoteCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	return &remoteCore{LevelEnabler: c.LevelEnabler, enc: enc, sink: c.sink}
+}
+
+func (c *remoteCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *remoteCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+
+	line := bytes.TrimRight(buf.Bytes(), "\n")
+	e := remoteEntry{level: ent.Level, time: ent.Time, line: append([]byte(nil), line...)}
+	buf.Free()
+
+	c.sink.push(e)
+	if ent.Level > zapcore.ErrorLevel {
+		// 进程可能马上退出, 最多等待 Timeout
+		c.sink.flush(c.sink.r.Timeout)
+	}
+	return nil
+}
+
+// Sync flush buffered entries, wait at most Timeout
+func (c *remoteCore) Sync() error {
+	c.sink.flush(c.sink.r.Timeout)
+	return nil
+}
+
+// RemoteLogStats return counters of remote shipping of DefaultLogger, see WithRemote.
+func RemoteLogStats() (RemoteStats, bool) {
+	l, ok := log.DefaultLogger.(*zaplog)
+	if !ok || l.remote == nil {
+		return RemoteStats{}, false
+	}
+	return l.remote.stats(), true
+}
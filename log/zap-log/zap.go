@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -13,12 +15,22 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// TraceLevel zap has no trace level, it's one level lower than debug.
+const TraceLevel = zapcore.DebugLevel - 1
+
 type zaplog struct {
 	cfg  zap.Config
-	zap  *zap.Logger
+	zap  *zap.Logger // base with fields
+	base *zap.Logger // without fields set by Fields
 	opts log.Options
 	sync.RWMutex
-	fields map[string]interface{}
+	fields map[string]interface{} // all fields set by Fields, including parents'
+
+	name   string  // name of logger, see Named
+	levels *levels // shared with loggers derived from this one
+
+	rateLimit *rateLimitCore // nil if no rate limits
+	remote    *remoteSink    // nil if not shipping to remote
 }
 
 func (l *zaplog) Init(opts ...log.Option) error {
@@ -39,51 +51,90 @@ func (l *zaplog) Init(opts ...log.Option) error {
 	if zcconfig, ok := l.opts.Context.Value(encoderConfigKey{}).(zapcore.EncoderConfig); ok {
 		zapConfig.EncoderConfig = zcconfig
 	}
+	zapConfig.EncoderConfig.EncodeLevel = traceLevelEncoder(zapConfig.EncoderConfig.EncodeLevel)
 
 	skip, ok := l.opts.Context.Value(callerSkipKey{}).(int)
 	if !ok || skip < 1 {
 		skip = 1
 	}
 
-	var enc zapcore.Encoder
-	if zapConfig.Encoding == "json" {
-		enc = zapcore.NewJSONEncoder(zapConfig.EncoderConfig)
-	} else {
-		enc = zapcore.NewConsoleEncoder(zapConfig.EncoderConfig)
+	// cores 使用 levels, 以支持运行时修改级别及按 logger 覆盖级别
+	lv := newLevels(zapConfig.Level)
+
+	rotate, ok := l.opts.Context.Value(rotateKey{}).(Rotate)
+	if !ok {
+		rotate = DefaultRotate
 	}
 
 	isOutput2Consone := l.opts.IsOutputToConsole
-	coreList := make([]zapcore.Core, 0, 2)
+	coreList := make([]zapcore.Core, 0, 3)
 	if len(l.opts.LogFileName) > 0 {
-		hook := &lumberjack.Logger{
-			Filename:   l.opts.LogFileName,
-			MaxSize:    100, // megabytes
-			MaxBackups: 10,
-			MaxAge:     10, // days
-			LocalTime:  true,
-			Compress:   true,
-		}
-
-		fileWriter := zapcore.AddSync(hook)
-
-		core := zapcore.NewCore(enc, fileWriter, zapConfig.Level)
+		enc := newEncoder(zapConfig.Encoding, zapConfig.EncoderConfig)
+		core := zapcore.NewCore(enc, newFileWriter(l.opts.LogFileName, rotate), lv)
 		coreList = append(coreList, core)
 	} else {
 		// 如果不输出到日志文件，那强制输出到console
 		isOutput2Consone = true
 	}
 
+	// error 及以上级别额外写一份到单独的文件
+	if errFileName, ok := l.opts.Context.Value(errorLogFileNameKey{}).(string); ok && len(errFileName) > 0 {
+		encoding := zapConfig.Encoding
+		if e, ok := l.opts.Context.Value(errorEncodingKey{}).(string); ok && len(e) > 0 {
+			encoding = e
+		}
+
+		errEnabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl >= zapcore.ErrorLevel && lv.Enabled(lvl)
+		})
+
+		enc := newEncoder(encoding, zapConfig.EncoderConfig)
+		core := zapcore.NewCore(enc, newFileWriter(errFileName, rotate), errEnabler)
+		coreList = append(coreList, core)
+	}
+
 	if isOutput2Consone {
+		encoding := "console"
+		if e, ok := l.opts.Context.Value(consoleEncodingKey{}).(string); ok && len(e) > 0 {
+			encoding = e
+		}
+
 		consoleDebugging := zapcore.Lock(os.Stdout)
-		consoleEncoder := zapcore.NewConsoleEncoder(zapConfig.EncoderConfig)
+		consoleEncoder := newEncoder(encoding, zapConfig.EncoderConfig)
 
-		core := zapcore.NewCore(consoleEncoder, consoleDebugging, zapConfig.Level)
+		core := zapcore.NewCore(consoleEncoder, consoleDebugging, lv)
 		coreList = append(coreList, core)
 	}
 
-	log := zap.New(zapcore.NewTee(coreList...),
+	// 发送到远端收集器, 固定使用 json 编码
+	var remote *remoteSink
+	if r, ok := l.opts.Context.Value(remoteKey{}).(Remote); ok {
+		var err error
+		if remote, err = newRemoteSink(r); err != nil {
+			return err
+		}
+		coreList = append(coreList, newRemoteCore(zapConfig.EncoderConfig, remote, lv))
+	}
+
+	core := zapcore.NewTee(coreList...)
+	if sampling, ok := l.opts.Context.Value(samplingKey{}).(Sampling); ok && sampling.Initial > 0 {
+		tick := sampling.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, sampling.Initial, sampling.Thereafter)
+	}
+
+	var rateLimit *rateLimitCore
+	if limits, ok := l.opts.Context.Value(rateLimitsKey{}).(map[string]int); ok {
+		if rateLimit = newRateLimitCore(core, limits); rateLimit != nil {
+			core = rateLimit
+		}
+	}
+
+	log := zap.New(core,
 		zap.AddCaller(),
-		zap.AddCallerSkip(skip),
+		zap.AddCallerSkip(skip+1), // +1 for zaplog.write
 		zap.AddStacktrace(zapcore.ErrorLevel))
 
 	// Adding seed fields if exist
@@ -102,121 +153,169 @@ func (l *zaplog) Init(opts ...log.Option) error {
 
 	l.cfg = zapConfig
 	l.zap = log
+	l.base = log
 	l.fields = make(map[string]interface{})
+	l.levels = lv
+	l.rateLimit = rateLimit
+	old := l.remote
+	l.remote = remote
+
+	// 重新 Init 时停止旧的 remote, 否则 goroutine 和连接泄漏
+	old.closeIfNot(remote)
 
 	return nil
 }
 
-func (l *zaplog) Fields(fields map[string]interface{}) *log.Helper {
-	l.Lock()
-	nfields := make(map[string]interface{}, len(l.fields))
-	for k, v := range l.fields {
-		nfields[k] = v
+func newEncoder(encoding string, cfg zapcore.EncoderConfig) zapcore.Encoder {
+	if encoding == "json" {
+		return zapcore.NewJSONEncoder(cfg)
 	}
-	l.Unlock()
-	for k, v := range fields {
-		nfields[k] = v
+	return zapcore.NewConsoleEncoder(cfg)
+}
+
+func newFileWriter(fileName string, rotate Rotate) zapcore.WriteSyncer {
+	hook := &lumberjack.Logger{
+		Filename:   fileName,
+		MaxSize:    rotate.MaxSize, // megabytes
+		MaxBackups: rotate.MaxBackups,
+		MaxAge:     rotate.MaxAge, // days
+		LocalTime:  true,
+		Compress:   rotate.Compress,
 	}
 
-	data := make([]zap.Field, 0, len(nfields))
-	for k, v := range fields {
-		data = append(data, zap.Any(k, v))
+	return zapcore.AddSync(hook)
+}
+
+// traceLevelEncoder encode TraceLevel as "TRACE" or "trace", others by enc
+func traceLevelEncoder(enc zapcore.LevelEncoder) zapcore.LevelEncoder {
+	if enc == nil {
+		enc = zapcore.LowercaseLevelEncoder
 	}
 
-	zl := &zaplog{
-		cfg:    l.cfg,
-		zap:    l.zap.With(data...),
-		opts:   l.opts,
-		fields: make(map[string]interface{}),
+	return func(lvl zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		if lvl != TraceLevel {
+			enc(lvl, pae)
+			return
+		}
+
+		// 通过编码 DebugLevel 判断大小写
+		var probe levelProbe
+		enc(zapcore.DebugLevel, &probe)
+		if probe.s == "debug" {
+			pae.AppendString("trace")
+		} else {
+			pae.AppendString("TRACE")
+		}
 	}
+}
 
-	return log.NewHelper(zl)
+// levelProbe capture the string appended by a LevelEncoder
+type levelProbe struct {
+	zapcore.PrimitiveArrayEncoder
+	s string
 }
 
-func (l *zaplog) Log(level log.Level, msg string, args ...interface{}) {
+func (p *levelProbe) AppendString(s string) {
+	p.s = s
+}
+
+// Fields return a child logger with fields of l and fields, fields override those of l with the same key.
+func (l *zaplog) Fields(fields map[string]interface{}) *log.Helper {
 	l.RLock()
-	data := make([]zap.Field, 0, len(l.fields))
+	nfields := make(map[string]interface{}, len(l.fields)+len(fields))
 	for k, v := range l.fields {
-		data = append(data, zap.Any(k, v))
+		nfields[k] = v
 	}
 	l.RUnlock()
+	for k, v := range fields {
+		nfields[k] = v
+	}
 
-	if len(args) > 0 {
-		var sz = len(args)
-		for i := 0; i < sz; i += 2 {
-			k, ok := args[i].(string)
-			var v interface{}
-			if !ok {
-				k, v = "ErrorKey", k
-			}
-
-			if (i + 1) < sz {
-				v = args[i+1]
-			}
+	return log.NewHelper(l.derive(l.base, l.name, nfields))
+}
 
-			data = append(data, zap.Any(k, v))
-		}
+// derive return a logger sharing settings with l, base and fields are replaced.
+func (l *zaplog) derive(base *zap.Logger, name string, fields map[string]interface{}) *zaplog {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys) // 固定输出顺序
 
-	reqID := ""
-	value, ok := GetReqIDForGoroutine()
-	if ok {
-		reqID = value.(string)
-	}
-	if len(reqID) > 0 {
-		data = append(data, zap.String("requestID", reqID))
+	data := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		data = append(data, zap.Any(k, log.RedactField(k, fields[k])))
 	}
 
-	lvl := loggerToZapLevel(level)
-	switch lvl {
-	case zap.DebugLevel:
-		l.zap.Debug(msg, data...)
-	case zap.InfoLevel:
-		l.zap.Info(msg, data...)
-	case zap.WarnLevel:
-		l.zap.Warn(msg, data...)
-	case zap.ErrorLevel:
-		l.zap.Error(msg, data...)
-	case zap.FatalLevel:
-		l.zap.Fatal(msg, data...)
+	return &zaplog{
+		cfg:       l.cfg,
+		zap:       base.With(data...),
+		base:      base,
+		opts:      l.opts,
+		fields:    fields,
+		name:      name,
+		levels:    l.levels,
+		rateLimit: l.rateLimit,
+		remote:    l.remote,
 	}
 }
 
+func (l *zaplog) Log(level log.Level, msg string, args ...interface{}) {
+	l.write(level, msg, l.buildFields(args, nil))
+}
+
 func (l *zaplog) Logf(level log.Level, format string, args ...interface{}) {
-	l.RLock()
-	data := make([]zap.Field, 0, len(l.fields))
-	for k, v := range l.fields {
-		data = append(data, zap.Any(k, v))
-	}
-	l.RUnlock()
+	l.write(level, fmt.Sprintf(format, args...), l.buildFields(nil, nil))
+}
 
-	reqID := ""
-	value, ok := GetReqIDForGoroutine()
-	if ok {
-		reqID = value.(string)
-	}
-	if len(reqID) > 0 {
-		data = append(data, zap.String("requestID", reqID))
+func (l *zaplog) LogContext(ctx context.Context, level log.Level, msg string, args ...interface{}) {
+	l.write(level, msg, l.buildFields(args, log.ContextFields(ctx)))
+}
+
+// buildFields args and ctxArgs as key-value pairs, fields of logger are already in l.zap
+func (l *zaplog) buildFields(args []interface{}, ctxArgs []interface{}) []zap.Field {
+	data := make([]zap.Field, 0, (len(args)+len(ctxArgs))/2)
+	data = appendArgs(data, args)
+	data = appendArgs(data, ctxArgs)
+	return data
+}
+
+func appendArgs(data []zap.Field, args []interface{}) []zap.Field {
+	var sz = len(args)
+	for i := 0; i < sz; i += 2 {
+		k, ok := args[i].(string)
+		var v interface{}
+		if !ok {
+			k, v = "ErrorKey", k
+		}
+
+		if (i + 1) < sz {
+			v = args[i+1]
+		}
+
+		data = append(data, zap.Any(k, log.RedactField(k, v)))
 	}
+	return data
+}
 
+func (l *zaplog) write(level log.Level, msg string, data []zap.Field) {
 	lvl := loggerToZapLevel(level)
-	msg := fmt.Sprintf(format, args...)
-	switch lvl {
-	case zap.DebugLevel:
-		l.zap.Debug(msg, data...)
-	case zap.InfoLevel:
-		l.zap.Info(msg, data...)
-	case zap.WarnLevel:
-		l.zap.Warn(msg, data...)
-	case zap.ErrorLevel:
-		l.zap.Error(msg, data...)
-	case zap.FatalLevel:
-		l.zap.Fatal(msg, data...)
+	if !l.levels.enabled(l.name, lvl) {
+		return
+	}
+
+	// Check 支持 TraceLevel, FatalLevel 写完后退出
+	if ce := l.zap.Check(lvl, msg); ce != nil {
+		ce.Write(data...)
 	}
 }
 
+// Close sync and stop shipping to remote, loggers derived from l share the remote.
 func (l *zaplog) Close() {
 	l.zap.Sync()
+	if l.remote != nil {
+		l.remote.close(l.remote.r.Timeout)
+	}
 }
 
 func (l *zaplog) String() string {
@@ -224,7 +323,10 @@ func (l *zaplog) String() string {
 }
 
 func (l *zaplog) Options() log.Options {
-	return l.opts
+	opts := l.opts
+	// 级别可能在运行时被修改
+	opts.Level = zapToLoggerLevel(l.levels.lookup(l.name))
+	return opts
 }
 
 // NewLogger New builds a new logger based on options
@@ -242,7 +344,13 @@ func NewLogger(asDefault bool, opts ...log.Option) (log.Logger, error) {
 	}
 
 	if asDefault {
+		old, _ := log.DefaultLogger.(*zaplog)
 		log.DefaultLogger = l
+
+		// e.g. InitByConfig again, the replaced logger is not used any more
+		if old != nil {
+			old.remote.closeIfNot(l.remote)
+		}
 	}
 
 	return l, nil
@@ -250,7 +358,9 @@ func NewLogger(asDefault bool, opts ...log.Option) (log.Logger, error) {
 
 func loggerToZapLevel(level log.Level) zapcore.Level {
 	switch level {
-	case log.TraceLevel, log.DebugLevel:
+	case log.TraceLevel:
+		return TraceLevel
+	case log.DebugLevel:
 		return zap.DebugLevel
 	case log.InfoLevel:
 		return zap.InfoLevel
@@ -267,6 +377,8 @@ func loggerToZapLevel(level log.Level) zapcore.Level {
 
 func zapToLoggerLevel(level zapcore.Level) log.Level {
 	switch level {
+	case TraceLevel:
+		return log.TraceLevel
 	case zap.DebugLevel:
 		return log.DebugLevel
 	case zap.InfoLevel:
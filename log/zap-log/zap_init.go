@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/robert-pkg/micro-go/log"
+	"github.com/robert-pkg/micro-go/rpc"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -18,12 +19,20 @@ func InitByConfig(c *log.LogConfig) (err error) {
 
 	level := log.InfoLevel
 	if len(c.Level) > 0 {
-		level, err = log.GetLevel("info")
+		level, err = log.GetLevel(c.Level)
 		if err != nil {
 			return err
 		}
 	}
 
+	if err := setBodyLogPolicy(c.BodyLog); err != nil {
+		return err
+	}
+
+	if c.Redact != nil {
+		log.SetRedactor(log.NewRedactor(c.Redact))
+	}
+
 	zCfg := zap.NewProductionConfig()
 	zCfg.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
 	zCfg.Encoding = c.Encoding
@@ -41,16 +50,122 @@ func InitByConfig(c *log.LogConfig) (err error) {
 	zCfg.EncoderConfig.EncodeDuration = zapcore.StringDurationEncoder
 	zCfg.EncoderConfig.EncodeCaller = zapcore.FullCallerEncoder //zapcore.ShortCallerEncoder
 
-	_, err = NewLogger(true,
+	rotate := DefaultRotate
+	if c.MaxSize > 0 {
+		rotate.MaxSize = c.MaxSize
+	}
+	if c.MaxBackups > 0 {
+		rotate.MaxBackups = c.MaxBackups
+	}
+	if c.MaxAge > 0 {
+		rotate.MaxAge = c.MaxAge
+	}
+	if c.Compress != nil {
+		rotate.Compress = *c.Compress
+	}
+
+	opts := []log.Option{
 		WithConfig(zCfg),
 		WithCallerSkip(2),
+		WithRotate(rotate),
+		WithErrorLogFileName(c.ErrorLogPath),
+		WithErrorEncoding(c.ErrorEncoding),
+		WithConsoleEncoding(c.ConsoleEncoding),
 		log.WithLevel(level),
 		log.WithOutputToConsole(c.OutputConsole),
 		log.WithLogFileName(c.LogPath),
-	)
+	}
+
+	if len(c.RateLimits) > 0 {
+		opts = append(opts, WithRateLimits(c.RateLimits))
+	}
+
+	if r := c.Remote; r != nil {
+		opts = append(opts, WithRemote(Remote{
+			Protocol:      r.Protocol,
+			Addr:          r.Addr,
+			Tag:           r.Tag,
+			Labels:        r.Labels,
+			Timeout:       r.Timeout,
+			BufferSize:    r.BufferSize,
+			BatchSize:     r.BatchSize,
+			FlushInterval: r.FlushInterval,
+			MaxRetries:    r.MaxRetries,
+		}))
+	}
+
+	if c.Sampling != nil {
+		opts = append(opts, WithSampling(Sampling{
+			Initial:    c.Sampling.Initial,
+			Thereafter: c.Sampling.Thereafter,
+			Tick:       c.Sampling.Tick,
+		}))
+	}
+
+	l, err := NewLogger(true, opts...)
 	if err != nil {
 		return err
 	}
 
+	return applyLoggerLevels(l.(*zaplog), c.Loggers)
+}
+
+// ReloadByConfig apply levels in c to DefaultLogger, other settings are ignored.
+// it's used to reload log config on SIGHUP, see appbase.SetLogReloader.
+func ReloadByConfig(c *log.LogConfig) error {
+	if c == nil {
+		return errors.New("no log config")
+	}
+
+	l, ok := log.DefaultLogger.(*zaplog)
+	if !ok {
+		return errors.New("default logger is not zap")
+	}
+
+	level := log.InfoLevel
+	if len(c.Level) > 0 {
+		var err error
+		level, err = log.GetLevel(c.Level)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := applyLoggerLevels(l, c.Loggers); err != nil {
+		return err
+	}
+	l.SetLevel("", level)
+
+	if c.BodyLog == nil {
+		// 配置中删除了 body_log, 恢复为默认策略
+		rpc.SetBodyLogPolicy(rpc.BodyLogPolicy{Mode: rpc.BodyLogOn})
+	} else if err := setBodyLogPolicy(c.BodyLog); err != nil {
+		return err
+	}
+
+	log.Info("reload log config", "level", level.String(), "loggers", c.Loggers)
 	return nil
 }
+
+// applyLoggerLevels replace levels of named loggers
+func applyLoggerLevels(l *zaplog, loggers map[string]string) error {
+	m := make(map[string]zapcore.Level, len(loggers))
+	for name, levelStr := range loggers {
+		level, err := log.GetLevel(levelStr)
+		if err != nil {
+			return err
+		}
+		m[name] = loggerToZapLevel(level)
+	}
+
+	l.levels.reset(m)
+	return nil
+}
+
+// setBodyLogPolicy apply body log policy of rpc clients and servers, nothing is done if c is nil
+func setBodyLogPolicy(c *log.BodyLogConfig) error {
+	if c == nil {
+		return nil
+	}
+	return rpc.SetBodyLogPolicyByConfig(&rpc.BodyLogConfig{Mode: c.Mode, MaxBytes: c.MaxBytes})
+}
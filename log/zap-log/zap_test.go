@@ -0,0 +1,190 @@
+package zap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	jaeger_go "github.com/uber/jaeger-client-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/robert-pkg/micro-go/log"
+	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/metadata"
+	// trace registers the extractor of trace_id and span_id
+	_ "github.com/robert-pkg/micro-go/trace"
+)
+
+// newObserved return a logger writing to an observer core, which shares levels with the logger
+func newObserved(t *testing.T, level log.Level) (*zaplog, *observer.ObservedLogs) {
+	t.Helper()
+
+	l, err := NewLogger(false, log.WithLevel(level))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zl := l.(*zaplog)
+	core, logs := observer.New(zl.levels)
+	zl.zap = zap.New(core, zap.WithFatalHook(zapcore.WriteThenPanic))
+	zl.base = zl.zap
+	return zl, logs
+}
+
+func TestLevels(t *testing.T) {
+	l, logs := newObserved(t, log.TraceLevel)
+	h := log.NewHelper(l)
+
+	h.Trace("trace")
+	h.Debug("debug")
+	h.Info("info")
+	h.Warn("warn")
+	h.Error("error")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("fatal did not panic with WriteThenPanic")
+			}
+		}()
+		h.Fatal("fatal")
+	}()
+
+	want := []struct {
+		level zapcore.Level
+		msg   string
+	}{
+		{TraceLevel, "trace"},
+		{zapcore.DebugLevel, "debug"},
+		{zapcore.InfoLevel, "info"},
+		{zapcore.WarnLevel, "warn"},
+		{zapcore.ErrorLevel, "error"},
+		{zapcore.FatalLevel, "fatal"},
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if entries[i].Level != w.level || entries[i].Message != w.msg {
+			t.Errorf("entry %d: got %v %q, want %v %q", i, entries[i].Level, entries[i].Message, w.level, w.msg)
+		}
+	}
+}
+
+func TestTraceLevelFiltered(t *testing.T) {
+	l, logs := newObserved(t, log.DebugLevel)
+	h := log.NewHelper(l)
+
+	h.Trace("trace")
+	h.Debug("debug")
+	if n := logs.FilterMessage("trace").Len(); n != 0 {
+		t.Errorf("trace logged at debug level: %d", n)
+	}
+	if n := logs.FilterMessage("debug").Len(); n != 1 {
+		t.Errorf("debug entries: %d", n)
+	}
+
+	l.SetLevel("", log.TraceLevel)
+	h.Trace("trace")
+	if n := logs.FilterMessage("trace").Len(); n != 1 {
+		t.Errorf("trace entries after SetLevel: %d", n)
+	}
+
+	if got := l.Options().Level; got != log.TraceLevel {
+		t.Errorf("Options().Level: got %v", got)
+	}
+}
+
+func TestNestedFields(t *testing.T) {
+	l, logs := newObserved(t, log.InfoLevel)
+
+	parent := l.Fields(map[string]interface{}{"a": 1, "b": "parent"})
+	child := parent.Fields(map[string]interface{}{"b": "child", "c": true})
+
+	child.Info("child")
+	parent.Info("parent")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+
+	m := entries[0].ContextMap()
+	if m["a"] != int64(1) || m["b"] != "child" || m["c"] != true {
+		t.Errorf("child fields: %v", m)
+	}
+
+	// 子 logger 的字段不影响父 logger
+	m = entries[1].ContextMap()
+	if m["b"] != "parent" || m["c"] != nil {
+		t.Errorf("parent fields: %v", m)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	l, logs := newObserved(t, log.InfoLevel)
+
+	fl := l.Fields(map[string]interface{}{"a": 1}).Logger.(*zaplog)
+	rpcLog := fl.Named("rpc").(*zaplog)
+	clientLog := rpcLog.Named("client")
+
+	log.NewHelper(clientLog).Info("named")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if entries[0].LoggerName != "rpc.client" {
+		t.Errorf("logger name: %q", entries[0].LoggerName)
+	}
+	if m := entries[0].ContextMap(); m["a"] != int64(1) {
+		t.Errorf("fields of parent are lost: %v", m)
+	}
+
+	// "rpc" 的级别作用于 "rpc.client"
+	l.SetLevel("rpc", log.DebugLevel)
+	log.NewHelper(clientLog).Debug("named debug")
+	log.NewHelper(l).Debug("root debug")
+	if n := logs.FilterMessage("named debug").Len(); n != 1 {
+		t.Errorf("named debug entries: %d", n)
+	}
+	if n := logs.FilterMessage("root debug").Len(); n != 0 {
+		t.Errorf("root debug entries: %d", n)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	l, logs := newObserved(t, log.InfoLevel)
+
+	tracer, closer := jaeger_go.NewTracer("test", jaeger_go.NewConstSampler(true), jaeger_go.NewNullReporter())
+	defer closer.Close()
+
+	span := tracer.StartSpan("op")
+	defer span.Finish()
+	sc := span.Context().(jaeger_go.SpanContext)
+
+	ctx := metadata.Set(context.Background(), rpc.RequestID, "req-1")
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	h := log.NewHelper(l).Fields(map[string]interface{}{"a": 1}).With(ctx)
+	h.Info("with ctx")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+
+	m := entries[0].ContextMap()
+	if m["requestID"] != "req-1" {
+		t.Errorf("requestID: %v", m["requestID"])
+	}
+	if m["trace_id"] != sc.TraceID().String() || m["span_id"] != sc.SpanID().String() {
+		t.Errorf("trace_id, span_id: %v %v", m["trace_id"], m["span_id"])
+	}
+	if m["a"] != int64(1) {
+		t.Errorf("fields: %v", m)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	consul "github.com/hashicorp/consul/api"
@@ -18,6 +19,9 @@ var (
 	defaultConsulAddr = "127.0.0.1:8500"
 )
 
+// maxListConcurrency concurrent queries of ListServices
+const maxListConcurrency = 8
+
 type consulRegistry struct {
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -163,6 +167,7 @@ func (c *consulRegistry) Register(s *registry.Service, opts ...registry.Register
 			Name:    s.Name,
 			Port:    port,
 			Address: hostIP,
+			Meta:    serviceMeta(node.Metadata),
 			Check:   check,
 		}
 
@@ -244,7 +249,7 @@ func (c *consulRegistry) GetService(name string, opts ...registry.GetOption) (ma
 			svc.Nodes = append(svc.Nodes, &registry.Node{
 				Id:       entry.Service.ID,
 				Address:  fmt.Sprintf("%s:%d", entry.Service.Address, entry.Service.Port), // ip地址和端口
-				Metadata: make(map[string]string),
+				Metadata: nodeMetadata(entry.Service.Meta),
 			})
 
 			key := fmt.Sprintf("%s:%d", entry.Service.Address, entry.Service.Port)
@@ -255,6 +260,89 @@ func (c *consulRegistry) GetService(name string, opts ...registry.GetOption) (ma
 	return resultMap, nil
 }
 
+func (c *consulRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+
+	var lo registry.ListOptions
+	for _, o := range opts {
+		o(&lo)
+	}
+
+	ctx := lo.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	names, _, err := c.client.Catalog().Services((&consul.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		log.Error("err", "error", err)
+		return nil, err
+	}
+
+	nameList := make([]string, 0, len(names))
+	for name := range names {
+		if name != "consul" {
+			nameList = append(nameList, name)
+		}
+	}
+
+	// 每个服务一次查询, 并发执行, 第一个错误取消其它查询
+	services := make([]*registry.Service, len(nameList))
+	errs := make([]error, len(nameList))
+	sem := make(chan struct{}, maxListConcurrency)
+	var wg sync.WaitGroup
+	for i, name := range nameList {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			services[i], errs[i] = c.listService(ctx, name)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Error("err", "error", err)
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// listService all nodes of service name, including unhealthy ones
+func (c *consulRegistry) listService(ctx context.Context, name string) (*registry.Service, error) {
+	entryList, _, err := c.client.Health().Service(name, "", false, (&consul.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &registry.Service{
+		Name:  name,
+		Nodes: make([]*registry.Node, 0, len(entryList)),
+	}
+
+	for _, entry := range entryList {
+		svc.Nodes = append(svc.Nodes, &registry.Node{
+			Id:       entry.Service.ID,
+			Address:  fmt.Sprintf("%s:%d", entry.Service.Address, entry.Service.Port), // ip地址和端口
+			Metadata: nodeMetadata(entry.Service.Meta),
+		})
+	}
+
+	return svc, nil
+}
+
 func (c *consulRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
 	return newConsulWatcher(c, opts...)
 }
@@ -1,6 +1,8 @@
 package consul
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/robert-pkg/micro-go/registry"
 )
@@ -22,3 +24,36 @@ func InitRegistryAsDefault(c *registry.Config) registry.Registry {
 	registry.DefaultRegistry = InitRegistry(c)
 	return registry.DefaultRegistry
 }
+
+// serviceMeta node metadata -> consul service meta, consul 只接受由字母, 数字, _ 和 - 组成的 key
+func serviceMeta(md map[string]string) map[string]string {
+	meta := make(map[string]string, len(md))
+	for k, v := range md {
+		if validMetaKey(k) {
+			meta[k] = v
+		}
+	}
+	return meta
+}
+
+func validMetaKey(k string) bool {
+	if len(k) <= 0 || len(k) > 64 || strings.HasPrefix(k, "consul-") {
+		return false
+	}
+
+	for _, r := range k {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeMetadata consul service meta -> node metadata
+func nodeMetadata(meta map[string]string) map[string]string {
+	md := make(map[string]string, len(meta))
+	for k, v := range meta {
+		md[k] = v
+	}
+	return md
+}
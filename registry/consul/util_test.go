@@ -0,0 +1,57 @@
+package consul
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidMetaKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "version", want: true},
+		{key: "http_port", want: true},
+		{key: "Zone-1", want: true},
+		{key: strings.Repeat("k", 64), want: true},
+		{key: "", want: false},
+		{key: strings.Repeat("k", 65), want: false},
+		{key: "consul-network-segment", want: false},
+		{key: "a.b", want: false},
+		{key: "a/b", want: false},
+		{key: "a b", want: false},
+		{key: "区域", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := validMetaKey(tt.key); got != tt.want {
+			t.Errorf("validMetaKey(%q): got %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestServiceMeta(t *testing.T) {
+	md := map[string]string{
+		"version":       "v1",
+		"protocol":      "grpc",
+		"weight.zone":   "10",
+		"consul-anchor": "x",
+		"":              "empty",
+	}
+
+	meta := serviceMeta(md)
+	want := map[string]string{"version": "v1", "protocol": "grpc"}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("serviceMeta: got %v, want %v", meta, want)
+	}
+
+	// md 不被修改
+	if len(md) != 5 {
+		t.Errorf("md is modified: %v", md)
+	}
+
+	if got := nodeMetadata(meta); !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeMetadata: got %v, want %v", got, want)
+	}
+}
@@ -99,7 +99,7 @@ func (cw *consulWatcher) serviceHandler(idx uint64, data interface{}) {
 			svc.Nodes = append(svc.Nodes, &registry.Node{
 				Id:       e.Service.ID,
 				Address:  fmt.Sprintf("%s:%d", e.Service.Address, e.Service.Port), // ip地址和端口
-				Metadata: make(map[string]string),
+				Metadata: nodeMetadata(e.Service.Meta),
 			})
 
 			// 新的实例
@@ -122,6 +122,10 @@ func (cw *consulWatcher) serviceHandler(idx uint64, data interface{}) {
 		cw.next <- &registry.Result{Action: "delete", Service: delService}
 	}
 
+	if cw.wo.Observer != nil {
+		cw.wo.Observer(cw.wo.Service, serviceNodes(cw.nodeMap), serviceNodes(deletedMap))
+	}
+
 	for _, newService := range newNodeMap {
 		cw.next <- &registry.Result{Action: "create", Service: newService}
 	}
@@ -168,3 +172,12 @@ func (cw *consulWatcher) Stop() {
 		}
 	}
 }
+
+// serviceNodes all nodes of services in m
+func serviceNodes(m map[string]*registry.Service) []*registry.Node {
+	nodes := make([]*registry.Node, 0, len(m))
+	for _, svc := range m {
+		nodes = append(nodes, svc.Nodes...)
+	}
+	return nodes
+}
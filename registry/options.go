@@ -29,10 +29,16 @@ type GetOptions struct {
 	Context context.Context
 }
 
+type ListOptions struct {
+	Context context.Context
+}
+
 type WatchOptions struct {
 	// Specify a service to watch
 	// If blank, the watch is for all services
 	Service string
+	// Observer is called by the watcher after each change, see WatchObserver
+	Observer func(service string, nodes, removed []*Node)
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -75,6 +81,12 @@ func GetContext(ctx context.Context) GetOption {
 	}
 }
 
+func ListContext(ctx context.Context) ListOption {
+	return func(o *ListOptions) {
+		o.Context = ctx
+	}
+}
+
 // Watch a service
 func WatchService(name string) WatchOption {
 	return func(o *WatchOptions) {
@@ -82,6 +94,14 @@ func WatchService(name string) WatchOption {
 	}
 }
 
+// WatchObserver f is called with all nodes of the watched service and the removed ones after each change,
+// e.g. to update metrics. it runs on the goroutine of the watcher and should not block.
+func WatchObserver(f func(service string, nodes, removed []*Node)) WatchOption {
+	return func(o *WatchOptions) {
+		o.Observer = f
+	}
+}
+
 func WatchContext(ctx context.Context) WatchOption {
 	return func(o *WatchOptions) {
 		o.Context = ctx
@@ -0,0 +1,148 @@
+// Package promsd builds prometheus service discovery targets from the registry,
+// for file_sd_configs (WriteFile) or http_sd_configs (Handler).
+package promsd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robert-pkg/micro-go/registry"
+)
+
+// labels of target groups
+const (
+	LabelService     = "service"
+	LabelNodeID      = "node_id"
+	LabelProtocol    = "protocol"
+	LabelMetricsPath = "__metrics_path__"
+)
+
+// TargetGroup prometheus file_sd / http_sd target group
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// TargetGroups one group per metrics address published by nodes as registry.MetadataMetricsAddr, other nodes are skipped.
+// nodes of an app share the admin server, e.g. its grpc and http servers, they are merged into one group:
+// labels are of the first node ordered by service and node id, protocols are joined by ",".
+func TargetGroups(services []*registry.Service) []*TargetGroup {
+	type target struct {
+		service string
+		node    *registry.Node
+	}
+
+	byAddr := make(map[string][]target)
+	for _, svc := range services {
+		for _, node := range svc.Nodes {
+			addr := node.Metadata[registry.MetadataMetricsAddr]
+			if len(addr) <= 0 {
+				continue
+			}
+			byAddr[addr] = append(byAddr[addr], target{service: svc.Name, node: node})
+		}
+	}
+
+	groups := make([]*TargetGroup, 0, len(byAddr))
+	for addr, targets := range byAddr {
+		sort.Slice(targets, func(i, j int) bool {
+			if targets[i].service != targets[j].service {
+				return targets[i].service < targets[j].service
+			}
+			return targets[i].node.Id < targets[j].node.Id
+		})
+
+		first := targets[0]
+		labels := map[string]string{
+			LabelService: first.service,
+			LabelNodeID:  first.node.Id,
+		}
+		if path := first.node.Metadata[registry.MetadataMetricsPath]; len(path) > 0 {
+			labels[LabelMetricsPath] = path
+		}
+
+		protocols := make([]string, 0, len(targets))
+		seen := make(map[string]bool, len(targets))
+		for _, t := range targets {
+			if protocol := t.node.Metadata["protocol"]; len(protocol) > 0 && !seen[protocol] {
+				seen[protocol] = true
+				protocols = append(protocols, protocol)
+			}
+		}
+		if len(protocols) > 0 {
+			sort.Strings(protocols)
+			labels[LabelProtocol] = strings.Join(protocols, ",")
+		}
+
+		groups = append(groups, &TargetGroup{Targets: []string{addr}, Labels: labels})
+	}
+
+	// 输出稳定, 避免文件内容无变化时也触发 prometheus 重新加载
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Targets[0] < groups[j].Targets[0]
+	})
+
+	return groups
+}
+
+// List target groups of all services in r
+func List(r registry.Registry, opts ...registry.ListOption) ([]*TargetGroup, error) {
+	services, err := r.ListServices(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return TargetGroups(services), nil
+}
+
+// WriteFile write target groups of r to path for file_sd_configs, the file is replaced atomically.
+func WriteFile(r registry.Registry, path string) error {
+	groups, err := List(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// TempFile 的权限为 0600, prometheus 可能以其它用户运行
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Handler serve target groups of r for http_sd_configs, e.g. mount it on the admin server.
+func Handler(r registry.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		groups, err := List(r, registry.ListContext(req.Context()))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(groups)
+	})
+}
@@ -0,0 +1,87 @@
+package promsd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/robert-pkg/micro-go/registry"
+)
+
+func node(id, protocol, metricsAddr string) *registry.Node {
+	md := map[string]string{"protocol": protocol}
+	if len(metricsAddr) > 0 {
+		md[registry.MetadataMetricsAddr] = metricsAddr
+		md[registry.MetadataMetricsPath] = "/metrics"
+	}
+	return &registry.Node{Id: id, Metadata: md}
+}
+
+func TestTargetGroups(t *testing.T) {
+	services := []*registry.Service{
+		{Name: "order-http", Nodes: []*registry.Node{node("order-http-1", "http", "10.0.0.1:9090")}},
+		{Name: "order", Nodes: []*registry.Node{
+			node("order-2", "grpc", "10.0.0.2:9090"),
+			node("order-1", "grpc", "10.0.0.1:9090"),
+		}},
+		{Name: "no-admin", Nodes: []*registry.Node{node("no-admin-1", "grpc", "")}},
+	}
+
+	want := []*TargetGroup{
+		{
+			Targets: []string{"10.0.0.1:9090"},
+			Labels: map[string]string{
+				LabelService:     "order",
+				LabelNodeID:      "order-1",
+				LabelProtocol:    "grpc,http",
+				LabelMetricsPath: "/metrics",
+			},
+		},
+		{
+			Targets: []string{"10.0.0.2:9090"},
+			Labels: map[string]string{
+				LabelService:     "order",
+				LabelNodeID:      "order-2",
+				LabelProtocol:    "grpc",
+				LabelMetricsPath: "/metrics",
+			},
+		},
+	}
+
+	got := TargetGroups(services)
+	if !reflect.DeepEqual(got, want) {
+		for _, g := range got {
+			t.Logf("%+v", *g)
+		}
+		t.Errorf("got %d groups, want %d", len(got), len(want))
+	}
+}
+
+type fakeRegistry struct {
+	registry.Registry
+	services []*registry.Service
+}
+
+func (r *fakeRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	return r.services, nil
+}
+
+func TestWriteFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.json")
+	r := &fakeRegistry{services: []*registry.Service{
+		{Name: "order", Nodes: []*registry.Node{node("order-1", "grpc", "10.0.0.1:9090")}},
+	}}
+
+	if err := WriteFile(r, path); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0644 {
+		t.Errorf("mode: got %o, want 644", mode)
+	}
+}
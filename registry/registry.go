@@ -10,6 +10,16 @@ var (
 	ErrWatcherStopped = errors.New("watcher stopped")
 )
 
+// keys of Node.Metadata, consul 中保存在 service meta
+const (
+	// MetadataMetricsAddr host:port serving prometheus metrics, see rpc/admin
+	MetadataMetricsAddr = "metrics_addr"
+	// MetadataMetricsPath e.g. /metrics
+	MetadataMetricsPath = "metrics_path"
+	// MetadataAdminPort port of admin server
+	MetadataAdminPort = "admin_port"
+)
+
 // The registry provides an interface for service discovery
 // and an abstraction over varying implementations
 // {consul, etcd, zookeeper, ...}
@@ -21,7 +31,7 @@ type Registry interface {
 	Deregister(*Service, ...DeregisterOption) error
 
 	GetService(string, ...GetOption) (map[string]*Service, error)
-	//ListServices(...ListOption) ([]*Service, error)
+	ListServices(...ListOption) ([]*Service, error)
 	Watch(...WatchOption) (Watcher, error)
 
 	String() string
@@ -62,6 +72,8 @@ type DeregisterOption func(*DeregisterOptions)
 
 type GetOption func(*GetOptions)
 
+type ListOption func(*ListOptions)
+
 type WatchOption func(*WatchOptions)
 
 // Register a service node. Additionally supply options such as TTL.
@@ -79,6 +91,11 @@ func GetService(name string) (map[string]*Service, error) {
 	return DefaultRegistry.GetService(name)
 }
 
+// ListServices return all services, each has all nodes of it.
+func ListServices() ([]*Service, error) {
+	return DefaultRegistry.ListServices()
+}
+
 // Watch returns a watcher which allows you to track updates to the registry.
 func Watch(opts ...WatchOption) (Watcher, error) {
 	return DefaultRegistry.Watch(opts...)
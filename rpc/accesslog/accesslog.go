@@ -0,0 +1,226 @@
+// Package accesslog writes access logs of rpc servers.
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// formats
+const (
+	FormatJSON = "json" // one json object per line
+	FormatText = "text" // key=value pairs
+)
+
+// Entry one access log
+type Entry struct {
+	Time      time.Time     `json:"time"`
+	Protocol  string        `json:"protocol"`       // http or grpc
+	Method    string        `json:"method"`         // http method, or grpc full method
+	Path      string        `json:"path,omitempty"` // http only
+	Status    int           `json:"status"`         // http status, or grpc code
+	Code      string        `json:"code,omitempty"` // grpc code name
+	Latency   time.Duration `json:"-"`
+	LatencyMs float64       `json:"latency_ms"`
+	Peer      string        `json:"peer"`
+	ReqSize   int64         `json:"req_size"`
+	RespSize  int64         `json:"resp_size"`
+	RequestID string        `json:"request_id,omitempty"`
+	UserID    string        `json:"user_id,omitempty"`
+	Err       string        `json:"err,omitempty"`
+}
+
+// Config 访问日志配置
+type Config struct {
+	Enable bool `yaml:"enable"`
+	// Format json(default), text, or text/template of Entry, e.g. "{{.Method}} {{.Path}} {{.Status}} {{.Latency}}"
+	Format string `yaml:"format"`
+	// Path 单独的日志文件, 为空则写入 log.DefaultLogger, 此时 Format 无效
+	Path       string `yaml:"path"`
+	MaxSize    int    `yaml:"max_size"`    // megabytes, default 100
+	MaxBackups int    `yaml:"max_backups"` // default 10
+	MaxAge     int    `yaml:"max_age"`     // days, default 10
+}
+
+// Logger write access logs
+type Logger struct {
+	mu     sync.Mutex
+	w      io.Writer // nil means log.DefaultLogger
+	closed bool
+	format string
+	tmpl   *template.Template
+}
+
+var std atomic.Value // NOTE: stored *Logger, nil if disabled
+
+// New create Logger by c.
+func New(c *Config) (*Logger, error) {
+	l := &Logger{format: c.Format}
+	switch c.Format {
+	case "", FormatJSON:
+		l.format = FormatJSON
+	case FormatText:
+	default:
+		tmpl, err := template.New("access").Parse(c.Format)
+		if err != nil {
+			return nil, err
+		}
+		l.tmpl = tmpl
+	}
+
+	if len(c.Path) > 0 {
+		w := &lumberjack.Logger{
+			Filename:   c.Path,
+			MaxSize:    100, // megabytes
+			MaxBackups: 10,
+			MaxAge:     10, // days
+			LocalTime:  true,
+			Compress:   true,
+		}
+		if c.MaxSize > 0 {
+			w.MaxSize = c.MaxSize
+		}
+		if c.MaxBackups > 0 {
+			w.MaxBackups = c.MaxBackups
+		}
+		if c.MaxAge > 0 {
+			w.MaxAge = c.MaxAge
+		}
+		l.w = w
+	}
+
+	return l, nil
+}
+
+// Init set the Logger used by servers, access log is disabled if c is nil or not enabled.
+func Init(c *Config) error {
+	if c == nil || !c.Enable {
+		swap(nil)
+		return nil
+	}
+
+	l, err := New(c)
+	if err != nil {
+		return err
+	}
+	swap(l)
+	return nil
+}
+
+// swap set l as the Logger used by servers, and close the previous one
+func swap(l *Logger) {
+	old, _ := std.Swap(l).(*Logger)
+	if old != nil {
+		old.Close()
+	}
+}
+
+// Enabled .
+func Enabled() bool {
+	l, _ := std.Load().(*Logger)
+	return l != nil
+}
+
+// Write write e by the Logger set by Init.
+func Write(e *Entry) {
+	if l, _ := std.Load().(*Logger); l != nil {
+		l.Write(e)
+	}
+}
+
+// Write .
+func (l *Logger) Write(e *Entry) {
+	e.LatencyMs = float64(e.Latency) / float64(time.Millisecond)
+
+	if l.w == nil {
+		log.Info("access", e.fields()...)
+		return
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case l.tmpl != nil:
+		if err := l.tmpl.Execute(&buf, e); err != nil {
+			log.Error("write access log fail", "err", err)
+			return
+		}
+	case l.format == FormatText:
+		args := e.fields()
+		buf.WriteString(e.Time.Format("2006-01-02 15:04:05.000"))
+		for i := 0; i < len(args); i += 2 {
+			fmt.Fprintf(&buf, " %s=%v", args[i], args[i+1])
+		}
+	default:
+		if err := json.NewEncoder(&buf).Encode(e); err != nil {
+			log.Error("write access log fail", "err", err)
+			return
+		}
+	}
+
+	if b := buf.Bytes(); len(b) <= 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	l.mu.Lock()
+	// lumberjack 关闭后写入会重新打开文件
+	if !l.closed {
+		l.w.Write(buf.Bytes())
+	}
+	l.mu.Unlock()
+}
+
+// Close close the log file, entries written after closed are dropped.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// fields key-value pairs for log.Logger and text format
+func (e *Entry) fields() []interface{} {
+	args := []interface{}{
+		"protocol", e.Protocol,
+		"method", e.Method,
+	}
+	if len(e.Path) > 0 {
+		args = append(args, "path", e.Path)
+	}
+	args = append(args, "status", e.Status)
+	if len(e.Code) > 0 {
+		args = append(args, "code", e.Code)
+	}
+	args = append(args,
+		"latency_ms", e.LatencyMs,
+		"peer", e.Peer,
+		"req_size", e.ReqSize,
+		"resp_size", e.RespSize)
+	if len(e.RequestID) > 0 {
+		args = append(args, "request_id", e.RequestID)
+	}
+	if len(e.UserID) > 0 {
+		args = append(args, "user_id", e.UserID)
+	}
+	if len(e.Err) > 0 {
+		args = append(args, "err", e.Err)
+	}
+	return args
+}
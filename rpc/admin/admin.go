@@ -0,0 +1,316 @@
+// Package admin serves /metrics, /healthz, /readyz, pprof and log level on a separate admin port.
+// pprof handlers are in package admin/pprof, see Config.Pprof.
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/robert-pkg/micro-go/log"
+	"github.com/robert-pkg/micro-go/registry"
+	"github.com/robert-pkg/micro-go/utils"
+)
+
+// paths
+const (
+	PathMetrics  = "/metrics"
+	PathHealthz  = "/healthz"
+	PathReadyz   = "/readyz"
+	PathPprof    = "/debug/pprof/"
+	PathLogLevel = "/log/level"
+)
+
+// Config admin 端口配置, 每个应用一份
+type Config struct {
+	Enable bool `yaml:"enable"`
+	// Addr e.g. ":9090", 为空时使用内网 ip 和随机端口
+	Addr string `yaml:"addr"`
+	// Pprof 是否暴露 /debug/pprof/, 需要引入 _ "github.com/robert-pkg/micro-go/rpc/admin/pprof"
+	Pprof bool `yaml:"pprof"`
+}
+
+// Server admin http server
+type Server struct {
+	mux     *http.ServeMux
+	httpSvr *http.Server
+	addr    string
+
+	closing int32 // atomic, not ready after Shutdown
+	mu      sync.Mutex
+	checks  map[string]func() error
+}
+
+var std atomic.Value // NOTE: stored *Server, nil if not started
+
+var pprofRegister atomic.Value // NOTE: stored func(*http.ServeMux), set by package admin/pprof
+
+// RegisterPprof set func to register pprof handlers, called by package admin/pprof.
+func RegisterPprof(f func(mux *http.ServeMux)) {
+	pprofRegister.Store(f)
+}
+
+// Readiness readiness of a component, e.g. a rpc server.
+// /readyz is ready only when all components are ready, so an app running grpc and http servers
+// is not ready until both are registered.
+type Readiness struct {
+	name string
+}
+
+var (
+	readyMu    sync.Mutex
+	components = make(map[*Readiness]bool)
+)
+
+// NewReadiness add a component which is not ready, it works before Init.
+func NewReadiness(name string) *Readiness {
+	r := &Readiness{name: name}
+
+	readyMu.Lock()
+	components[r] = false
+	readyMu.Unlock()
+
+	return r
+}
+
+// Set readiness of the component
+func (r *Readiness) Set(ready bool) {
+	readyMu.Lock()
+	components[r] = ready
+	readyMu.Unlock()
+}
+
+// Remove the component, e.g. it's stopped while the app keeps running.
+func (r *Readiness) Remove() {
+	readyMu.Lock()
+	delete(components, r)
+	readyMu.Unlock()
+}
+
+// notReady names of components which are not ready, sorted
+func notReady() []string {
+	readyMu.Lock()
+	defer readyMu.Unlock()
+
+	var names []string
+	for r, ready := range components {
+		if !ready {
+			names = append(names, r.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewServer create Server, /readyz is not ready until all components are ready, see NewReadiness.
+func NewServer(c *Config) *Server {
+	s := &Server{
+		mux:    http.NewServeMux(),
+		addr:   c.Addr,
+		checks: make(map[string]func() error),
+	}
+
+	s.mux.Handle(PathMetrics, promhttp.Handler())
+	s.mux.HandleFunc(PathHealthz, s.healthz)
+	s.mux.HandleFunc(PathReadyz, s.readyz)
+	s.mux.Handle(PathLogLevel, log.LevelHandler())
+
+	if c.Pprof {
+		if f, ok := pprofRegister.Load().(func(*http.ServeMux)); ok {
+			f(s.mux)
+		} else {
+			log.Warn("pprof is enabled, but package admin/pprof is not imported")
+		}
+	}
+
+	return s
+}
+
+// Handle register extra handler on admin port
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// AddReadyCheck add a check of /readyz, e.g. ping db, nil f removes the check.
+func (s *Server) AddReadyCheck(name string, f func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f == nil {
+		delete(s.checks, name)
+		return
+	}
+	s.checks[name] = f
+}
+
+// Start listen and serve in background
+func (s *Server) Start() error {
+
+	addr := s.addr
+	if len(addr) <= 0 {
+		ipList, err := utils.LocalInternalIP()
+		if err != nil {
+			return err
+		}
+
+		if len(ipList) <= 0 {
+			return errors.New("无可用IP地址")
+		}
+
+		addr = fmt.Sprintf("%s:%d", ipList[0], utils.RandPort())
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.addr = lis.Addr().String()
+	s.httpSvr = &http.Server{Handler: s.mux}
+
+	go func() {
+		if err := s.httpSvr.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Error("admin server fail", "addr", s.addr, "err", err)
+		}
+	}()
+
+	log.Info("start admin server", "addr", s.addr)
+	return nil
+}
+
+// Addr return the listening address after Start
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Shutdown .
+func (s *Server) Shutdown() error {
+	atomic.StoreInt32(&s.closing, 1)
+
+	if s.httpSvr == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.httpSvr.Shutdown(ctx)
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&s.closing) != 0 {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	if names := notReady(); len(names) > 0 {
+		http.Error(w, "not ready: "+strings.Join(names, ", "), http.StatusServiceUnavailable)
+		return
+	}
+
+	s.mu.Lock()
+	checks := make(map[string]func() error, len(s.checks))
+	for name, f := range s.checks {
+		checks[name] = f
+	}
+	s.mu.Unlock()
+
+	for name, f := range checks {
+		if err := f(); err != nil {
+			http.Error(w, fmt.Sprintf("%s: %v", name, err), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.Write([]byte("ok"))
+}
+
+// Init start the default admin server by c, nothing is done if c is nil or disabled.
+func Init(c *Config) error {
+	if c == nil || !c.Enable {
+		return nil
+	}
+
+	s := NewServer(c)
+	if err := s.Start(); err != nil {
+		return err
+	}
+
+	std.Store(s)
+	return nil
+}
+
+// Default return the default admin server, nil if not started.
+func Default() *Server {
+	s, _ := std.Load().(*Server)
+	return s
+}
+
+// Addr return address of the default admin server, empty if not started.
+func Addr() string {
+	if s := Default(); s != nil {
+		return s.Addr()
+	}
+	return ""
+}
+
+// AdvertiseAddr return address of the default admin server to register, empty if not started.
+// host replaces an unspecified listening host, e.g. ":9090" is advertised as host:9090.
+func AdvertiseAddr(host string) string {
+	s := Default()
+	if s == nil {
+		return ""
+	}
+
+	h, port, err := net.SplitHostPort(s.Addr())
+	if err != nil {
+		return s.Addr()
+	}
+
+	if ip := net.ParseIP(h); len(h) <= 0 || (ip != nil && ip.IsUnspecified()) {
+		h = host
+	}
+	return net.JoinHostPort(h, port)
+}
+
+// Metadata set metrics and admin address of the default admin server into registry node metadata,
+// host is the host of the node. nothing is done if not started.
+func Metadata(md map[string]string, host string) {
+	addr := AdvertiseAddr(host)
+	if len(addr) <= 0 {
+		return
+	}
+
+	md[registry.MetadataMetricsAddr] = addr
+	md[registry.MetadataMetricsPath] = PathMetrics
+	if _, port, err := net.SplitHostPort(addr); err == nil {
+		md[registry.MetadataAdminPort] = port
+	}
+}
+
+// AddReadyCheck add a check to the default admin server.
+func AddReadyCheck(name string, f func() error) {
+	if s := Default(); s != nil {
+		s.AddReadyCheck(name, f)
+	}
+}
+
+// Shutdown the default admin server.
+func Shutdown() error {
+	if s := Default(); s != nil {
+		return s.Shutdown()
+	}
+	return nil
+}
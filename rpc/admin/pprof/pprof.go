@@ -0,0 +1,27 @@
+// Package pprof serves /debug/pprof/ on the admin server if admin.Config.Pprof is set.
+//
+// it imports net/http/pprof, whose init also registers /debug/pprof/ on http.DefaultServeMux,
+// so it's a separate package imported only by apps which enable pprof:
+//
+//	import _ "github.com/robert-pkg/micro-go/rpc/admin/pprof"
+package pprof
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/robert-pkg/micro-go/rpc/admin"
+)
+
+func init() {
+	admin.RegisterPprof(Register)
+}
+
+// Register pprof handlers on mux under admin.PathPprof
+func Register(mux *http.ServeMux) {
+	mux.HandleFunc(admin.PathPprof, pprof.Index)
+	mux.HandleFunc(admin.PathPprof+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(admin.PathPprof+"profile", pprof.Profile)
+	mux.HandleFunc(admin.PathPprof+"symbol", pprof.Symbol)
+	mux.HandleFunc(admin.PathPprof+"trace", pprof.Trace)
+}
@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"fmt"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// BodyLogMode 请求/响应 body 的日志策略
+type BodyLogMode int
+
+const (
+	// BodyLogOn 完整输出 body
+	BodyLogOn BodyLogMode = iota
+	// BodyLogOff 不输出 body
+	BodyLogOff
+	// BodyLogTruncate 只输出前 MaxBytes 字节
+	BodyLogTruncate
+	// BodyLogOnError 只在出错时输出 body
+	BodyLogOnError
+)
+
+// BodyLogPolicy .
+type BodyLogPolicy struct {
+	Mode     BodyLogMode
+	MaxBytes int // used by BodyLogTruncate and BodyLogOnError, <= 0 means no limit
+}
+
+// BodyLogConfig .
+type BodyLogConfig struct {
+	Mode     string `yaml:"mode"` // on, off, truncate, error
+	MaxBytes int    `yaml:"max_bytes"`
+}
+
+var bodyLogPolicy atomic.Value // NOTE: stored BodyLogPolicy
+
+var bodyRedactor atomic.Value // NOTE: stored func([]byte) []byte
+
+func init() {
+	bodyLogPolicy.Store(BodyLogPolicy{Mode: BodyLogOn})
+}
+
+// SetBodyLogPolicy set body log policy of rpc clients and servers.
+func SetBodyLogPolicy(p BodyLogPolicy) {
+	bodyLogPolicy.Store(p)
+}
+
+// SetBodyLogPolicyByConfig .
+func SetBodyLogPolicyByConfig(c *BodyLogConfig) error {
+	if c == nil {
+		return nil
+	}
+
+	p := BodyLogPolicy{MaxBytes: c.MaxBytes}
+	switch c.Mode {
+	case "", "on":
+		p.Mode = BodyLogOn
+	case "off":
+		p.Mode = BodyLogOff
+	case "truncate":
+		p.Mode = BodyLogTruncate
+	case "error":
+		p.Mode = BodyLogOnError
+	default:
+		return fmt.Errorf("unknown body log mode: '%s'", c.Mode)
+	}
+
+	SetBodyLogPolicy(p)
+	return nil
+}
+
+// SetBodyRedactor set func to mask sensitive data in body before logging, see log.RedactBody.
+func SetBodyRedactor(f func(body []byte) []byte) {
+	bodyRedactor.Store(f)
+}
+
+// GetBodyLogPolicy .
+func GetBodyLogPolicy() BodyLogPolicy {
+	return bodyLogPolicy.Load().(BodyLogPolicy)
+}
+
+// LogBody return body for logging by the current policy, ok is false if body should not be logged.
+// isErr tells whether the call failed.
+func LogBody(body []byte, isErr bool) (string, bool) {
+	p := GetBodyLogPolicy()
+
+	switch p.Mode {
+	case BodyLogOff:
+		return "", false
+	case BodyLogOnError:
+		if !isErr {
+			return "", false
+		}
+	}
+
+	if f, ok := bodyRedactor.Load().(func([]byte) []byte); ok && f != nil {
+		body = f(body)
+	}
+
+	if p.Mode == BodyLogOn {
+		return string(body), true
+	}
+
+	if p.MaxBytes > 0 && len(body) > p.MaxBytes {
+		// 不能截断多字节字符, 否则日志中是非法的 utf8
+		n := p.MaxBytes
+		for n > 0 && !utf8.RuneStart(body[n]) {
+			n--
+		}
+		return fmt.Sprintf("%s...(%d bytes)", body[:n], len(body)), true
+	}
+	return string(body), true
+}
+
+// AppendBody append key, body to args if body should be logged.
+func AppendBody(args []interface{}, key string, body []byte, isErr bool) []interface{} {
+	if s, ok := LogBody(body, isErr); ok {
+		args = append(args, key, s)
+	}
+	return args
+}
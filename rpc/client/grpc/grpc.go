@@ -16,8 +16,10 @@ import (
 	"github.com/robert-pkg/micro-go/log"
 	"github.com/robert-pkg/micro-go/registry"
 	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/metrics"
 
 	rpc_metadata "github.com/robert-pkg/micro-go/rpc/metadata"
+	"github.com/robert-pkg/micro-go/rpc/status"
 	"github.com/robert-pkg/micro-go/trace"
 	grpc_go "google.golang.org/grpc"
 	grpc_metadata "google.golang.org/grpc/metadata"
@@ -70,7 +72,7 @@ func NewClient(serviceName string) (*Client, error) {
 		}
 	}
 
-	watcher, err := registry.Watch(registry.WatchService(c.serviceName))
+	watcher, err := registry.Watch(registry.WatchService(c.serviceName), registry.WatchObserver(metrics.ObserveInstances))
 	if err != nil {
 		return nil, err
 	}
@@ -250,39 +252,37 @@ func (c *Client) RawCall(ctx context.Context, method string, reqData []byte) ([]
 	c.applyChan <- struct{}{}
 	conn := <-c.grantChan
 	if conn == nil {
+		metrics.ClientStart(c.serviceName, method, "")(ErrNoAvailableConn)
 		return nil, ErrNoAvailableConn
 	}
 
-	newTraceID := ""
-	if tracer := opentracing.GlobalTracer(); tracer != nil {
+	if tracer := opentracing.GlobalTracer(); tracer != nil && !trace.IsSkipped(ctx) {
 
-		if parentSpanContext := ctx.Value("ParentSpanContext"); parentSpanContext == nil {
+		if trace.SpanFromContext(ctx) == nil {
 			var rootSpan opentracing.Span
-			rootSpan, newTraceID, ctx = trace.NewRootSpan(ctx, tracer, "grpc-call", reqID)
+			rootSpan, _, ctx = trace.NewRootSpan(ctx, tracer, "grpc-call", reqID)
 			defer rootSpan.Finish()
 		}
 	}
 
 	realMethodName := fmt.Sprintf("/%s.%s/%s", c.serviceName, c.shortServiceName, method)
 
-	if true {
-		args := make([]interface{}, 0, 6)
-		args = append(args, rpc.RequestID, reqID, "method", method, "body", string(reqData))
+	// requestID, trace_id 由 ctx 带上
+	log.InfoContext(ctx, "invoke grpc call", rpc.AppendBody([]interface{}{"method", method}, "body", reqData, false)...)
 
-		if len(newTraceID) > 0 {
-			args = append(args, "newTraceID", newTraceID)
-		}
-
-		log.Info("invoke grpc call", args...)
-	}
+	done := metrics.ClientStart(c.serviceName, method, conn.Target())
 
 	var out []byte
 	if err := conn.Invoke(ctx, realMethodName, reqData, &out); err != nil {
-		log.Error("invoke grpc call fail", rpc.RequestID, reqID, "method", method, "err", err)
-		return nil, err
+		log.ErrorContext(ctx, "invoke grpc call fail", rpc.AppendBody([]interface{}{"method", method, "err", err}, "body", reqData, true)...)
+		// grpc status -> ecode
+		ec := status.ToEcode(err)
+		done(ec)
+		return nil, ec
 	}
+	done(nil)
 
-	log.Info("invoke grpc call success", rpc.RequestID, reqID, "method", method, "reply", string(out))
+	log.InfoContext(ctx, "invoke grpc call success", rpc.AppendBody([]interface{}{"method", method}, "reply", out, false)...)
 
 	return out, nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/robert-pkg/micro-go/log"
 	"github.com/robert-pkg/micro-go/registry"
 	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/metrics"
 	"github.com/robert-pkg/micro-go/trace"
 )
 
@@ -57,7 +58,7 @@ func NewClient(serviceName string) (*Client, error) {
 		}
 	}
 
-	watcher, err := registry.Watch(registry.WatchService(c.serviceName))
+	watcher, err := registry.Watch(registry.WatchService(c.serviceName), registry.WatchObserver(metrics.ObserveInstances))
 	if err != nil {
 		return nil, err
 	}
@@ -221,39 +222,33 @@ func (c *Client) RawCall(ctx context.Context, method string, reqData []byte) ([]
 	c.applyChan <- struct{}{}
 	serverInstance := <-c.grantChan
 	if serverInstance == nil {
+		metrics.ClientStart(c.serviceName, method, "")(ErrNoAvailableConn)
 		return nil, ErrNoAvailableConn
 	}
 
-	newTraceID := ""
-	if tracer := opentracing.GlobalTracer(); tracer != nil {
+	if tracer := opentracing.GlobalTracer(); tracer != nil && !trace.IsSkipped(ctx) {
 
-		if parentSpanContext := ctx.Value("ParentSpanContext"); parentSpanContext == nil {
+		if trace.SpanFromContext(ctx) == nil {
 			var rootSpan opentracing.Span
-			rootSpan, newTraceID, ctx = trace.NewRootSpan(ctx, tracer, "http-call", reqID)
+			rootSpan, _, ctx = trace.NewRootSpan(ctx, tracer, "http-call", reqID)
 			defer rootSpan.Finish()
 		}
 	}
 
-	if true {
-		args := make([]interface{}, 0, 6)
-		args = append(args, rpc.RequestID, reqID, "method", method, "body", string(reqData))
-
-		if len(newTraceID) > 0 {
-			args = append(args, "newTraceID", newTraceID)
-		}
-
-		log.Info("invoke http call", args...)
-	}
+	// requestID, trace_id 由 ctx 带上
+	log.InfoContext(ctx, "invoke http call", rpc.AppendBody([]interface{}{"method", method}, "body", reqData, false)...)
 
 	url := fmt.Sprintf("http://%s/api/%s/%s", serverInstance.GetAddr(), c.shortServiceName, method)
 
+	done := metrics.ClientStart(c.serviceName, method, serverInstance.GetAddr())
 	out, err := serverInstance.Call(ctx, http.MethodPost, url, reqData)
+	done(err)
 	if err != nil {
-		log.Error("invoke http call fail", rpc.RequestID, reqID, "method", method, "err", err)
+		log.ErrorContext(ctx, "invoke http call fail", rpc.AppendBody([]interface{}{"method", method, "err", err}, "body", reqData, true)...)
 		return nil, err
 	}
 
-	log.Info("invoke grpc call success", rpc.RequestID, reqID, "method", method, "reply", string(out))
+	log.InfoContext(ctx, "invoke http call success", rpc.AppendBody([]interface{}{"method", method}, "reply", out, false)...)
 
 	return out, nil
 }
@@ -11,10 +11,11 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
-	"github.com/pkg/errors"
 	"github.com/robert-pkg/micro-go/log"
 	"github.com/robert-pkg/micro-go/registry"
 	"github.com/robert-pkg/micro-go/rpc/metadata"
+	"github.com/robert-pkg/micro-go/rpc/status"
+	"github.com/robert-pkg/micro-go/trace"
 )
 
 // 服务实例
@@ -54,18 +55,11 @@ func (instance *serverInstance) Call(ctx context.Context, method string, url str
 	}
 
 	tracer := opentracing.GlobalTracer()
-	if tracer != nil {
-
-		var parentCtx opentracing.SpanContext
-		if pc := ctx.Value("ParentSpanContext"); pc != nil {
-			if realPC, ok := pc.(opentracing.SpanContext); ok {
-				parentCtx = realPC
-			}
-		}
+	if tracer != nil && !trace.IsSkipped(ctx) {
 
 		span := tracer.StartSpan(
 			method,
-			opentracing.ChildOf(parentCtx), // can be nil
+			opentracing.ChildOf(trace.SpanContextFromContext(ctx)), // can be nil
 			opentracing.Tag{Key: string(ext.Component), Value: "HTTP"},
 			ext.SpanKindRPCClient,
 		)
@@ -93,8 +87,9 @@ func (instance *serverInstance) Call(ctx context.Context, method string, url str
 		return nil, err
 	}
 
-	if response.StatusCode != 200 {
-		err = errors.Errorf("error: %s", string(respBody))
+	// envelope -> data or ecode
+	respBody, err = status.DecodeResponse(response.StatusCode, response.Header, respBody)
+	if err != nil {
 		log.Error("err", "response.StatusCode", response.StatusCode, "err", err)
 		return nil, err
 	}
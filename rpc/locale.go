@@ -0,0 +1,26 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/robert-pkg/micro-go/ecode"
+	"github.com/robert-pkg/micro-go/rpc/metadata"
+)
+
+// LocalesFromContext return locales from Locale and AcceptLanguage in metadata,
+// custom locale comes first.
+func LocalesFromContext(ctx context.Context) []string {
+	var locales []string
+	if locale, ok := metadata.Get(ctx, Locale); ok && len(locale) > 0 {
+		locales = append(locales, locale)
+	}
+	if al, ok := metadata.Get(ctx, AcceptLanguage); ok {
+		locales = append(locales, ecode.ParseAcceptLanguage(al)...)
+	}
+	return locales
+}
+
+// MessageFromContext return message of ec in locale carried by ctx, see ecode.LocaleMessage.
+func MessageFromContext(ctx context.Context, ec ecode.Codes) string {
+	return ecode.LocaleMessage(ec, LocalesFromContext(ctx)...)
+}
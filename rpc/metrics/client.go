@@ -0,0 +1,118 @@
+// Package metrics prometheus metrics of rpc clients and servers, registered to prometheus.DefaultRegisterer.
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robert-pkg/micro-go/ecode"
+	"github.com/robert-pkg/micro-go/registry"
+)
+
+const namespace = "micro"
+
+var (
+	clientRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "client",
+		Name:      "requests_total",
+		Help:      "Total number of rpc calls made by clients.",
+	}, []string{"service", "method", "instance"})
+
+	clientErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "client",
+		Name:      "errors_total",
+		Help:      "Total number of failed rpc calls made by clients, by ecode.",
+	}, []string{"service", "method", "instance", "code"})
+
+	clientDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "client",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of rpc calls made by clients.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "instance"})
+
+	clientInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "client",
+		Name:      "requests_in_flight",
+		Help:      "Number of rpc calls in progress.",
+	}, []string{"service", "method", "instance"})
+
+	clientInstances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "client",
+		Name:      "instances",
+		Help:      "Number of instances of the target service found by the registry watcher.",
+	}, []string{"service"})
+)
+
+func init() {
+	prometheus.MustRegister(clientRequests, clientErrors, clientDuration, clientInFlight, clientInstances)
+}
+
+// instanceTokens live instances, key is service + "\x00" + instance, value is *struct{} replaced after deleted,
+// so that calls started before the instance is deleted do not recreate its series.
+var instanceTokens sync.Map
+
+func instanceKey(service, instance string) string {
+	return service + "\x00" + instance
+}
+
+// ClientStart record the start of a call to instance of service, the returned func must be called with the result.
+// instance is empty if no instance is available.
+func ClientStart(service, method, instance string) (done func(err error)) {
+	start := time.Now()
+	key := instanceKey(service, instance)
+	token, _ := instanceTokens.LoadOrStore(key, new(struct{}))
+
+	inFlight := clientInFlight.WithLabelValues(service, method, instance)
+	inFlight.Inc()
+
+	return func(err error) {
+		inFlight.Dec()
+
+		// 调用期间 instance 被删除, 不再重新创建它的 series
+		if cur, ok := instanceTokens.Load(key); !ok || cur != token {
+			return
+		}
+
+		clientRequests.WithLabelValues(service, method, instance).Inc()
+		clientDuration.WithLabelValues(service, method, instance).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			code := strconv.FormatInt(int64(ecode.Cause(err).Code()), 10)
+			clientErrors.WithLabelValues(service, method, instance, code).Inc()
+		}
+	}
+}
+
+// SetClientInstances set number of instances of service.
+func SetClientInstances(service string, n int) {
+	clientInstances.WithLabelValues(service).Set(float64(n))
+}
+
+// ObserveInstances update instance metrics of service by the registry watcher of clients, see registry.WatchObserver.
+// the address of a node is the instance label.
+func ObserveInstances(service string, nodes, removed []*registry.Node) {
+	for _, node := range removed {
+		DeleteClientInstance(service, node.Address)
+	}
+	SetClientInstances(service, len(nodes))
+}
+
+// DeleteClientInstance delete series of instance of service, called by the registry watcher when it's removed,
+// otherwise series of instances replaced by deploys are kept forever. requires client_golang v1.13+.
+func DeleteClientInstance(service, instance string) {
+	instanceTokens.Delete(instanceKey(service, instance))
+
+	labels := prometheus.Labels{"service": service, "instance": instance}
+	clientRequests.DeletePartialMatch(labels)
+	clientErrors.DeletePartialMatch(labels)
+	clientDuration.DeletePartialMatch(labels)
+	clientInFlight.DeletePartialMatch(labels)
+}
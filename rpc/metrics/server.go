@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RouteUnmatched route label of requests not matching any route, so that raw paths never become labels.
+const RouteUnmatched = "unmatched"
+
+var (
+	httpServerRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_server",
+		Name:      "requests_total",
+		Help:      "Total number of http requests handled, by route template and status.",
+	}, []string{"method", "route", "status"})
+
+	httpServerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http_server",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of http requests, by route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpServerInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http_server",
+		Name:      "requests_in_flight",
+		Help:      "Number of http requests in progress.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(httpServerRequests, httpServerDuration, httpServerInFlight)
+}
+
+// HTTPServerStart record the start of a http request, route is the route template, e.g. "/api/user/:id".
+// the returned func must be called with the response status.
+func HTTPServerStart(method, route string) (done func(status int)) {
+	if len(route) <= 0 {
+		route = RouteUnmatched
+	}
+
+	start := time.Now()
+	httpServerInFlight.Inc()
+
+	return func(status int) {
+		httpServerInFlight.Dec()
+		httpServerRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpServerDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -15,6 +15,14 @@ const (
 	UserID     string = "User-Id"     // title格式
 	DeviceType string = "Device-Type" // // title格式
 	SkipTrace  string = "Skip-Trace"
+	// TraceDebug 强制采样, 用于排查问题
+	TraceDebug string = "Trace-Debug"
+	// Locale 自定义语言标识, 优先于 AcceptLanguage
+	Locale string = "Locale"
+	// AcceptLanguage 标准语言标识
+	AcceptLanguage string = "Accept-Language"
+	// ErrorCode http 响应中的错误码标识, 存在时 body 为标准 envelope
+	ErrorCode string = "Error-Code" // title格式
 )
 
 // GetOrCreateReqIDFromCtx .
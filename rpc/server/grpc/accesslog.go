@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/accesslog"
+
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_stats "google.golang.org/grpc/stats"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+type accessEntryKey struct{}
+
+// accessLogHandler 访问日志, 通过 stats.Handler 拿到实际的收发字节数, 见 accesslog.Init
+type accessLogHandler struct{}
+
+func (h accessLogHandler) TagRPC(ctx context.Context, info *grpc_stats.RPCTagInfo) context.Context {
+	if !accesslog.Enabled() {
+		return ctx
+	}
+
+	e := &accesslog.Entry{Protocol: "grpc", Method: info.FullMethodName}
+	return context.WithValue(ctx, accessEntryKey{}, e)
+}
+
+func (h accessLogHandler) HandleRPC(ctx context.Context, s grpc_stats.RPCStats) {
+	e, ok := ctx.Value(accessEntryKey{}).(*accesslog.Entry)
+	if !ok {
+		return
+	}
+
+	// 同一个 rpc 的事件按顺序回调, 不需要加锁
+	switch st := s.(type) {
+	case *grpc_stats.Begin:
+		e.Time = st.BeginTime
+	case *grpc_stats.InHeader:
+		if st.RemoteAddr != nil {
+			e.Peer = st.RemoteAddr.String()
+		}
+		e.RequestID = firstValue(st.Header, rpc.RequestID)
+		e.UserID = firstValue(st.Header, rpc.UserID)
+	case *grpc_stats.InPayload:
+		e.ReqSize += int64(st.WireLength)
+	case *grpc_stats.OutPayload:
+		e.RespSize += int64(st.WireLength)
+	case *grpc_stats.End:
+		if e.Time.IsZero() {
+			e.Time = st.BeginTime
+		}
+		e.Latency = st.EndTime.Sub(e.Time)
+
+		code := grpc_status.Code(st.Error)
+		e.Status = int(code)
+		e.Code = code.String()
+		if st.Error != nil {
+			e.Err = st.Error.Error()
+		}
+
+		accesslog.Write(e)
+	}
+}
+
+func (h accessLogHandler) TagConn(ctx context.Context, info *grpc_stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h accessLogHandler) HandleConn(ctx context.Context, s grpc_stats.ConnStats) {
+}
+
+func firstValue(md grpc_metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
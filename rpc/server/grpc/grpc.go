@@ -8,6 +8,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/robert-pkg/micro-go/registry"
+	"github.com/robert-pkg/micro-go/rpc/admin"
+	"github.com/robert-pkg/micro-go/rpc/status"
 	"github.com/robert-pkg/micro-go/trace"
 	"github.com/robert-pkg/micro-go/utils"
 
@@ -38,20 +40,25 @@ type Server struct {
 
 	// registry service instance
 	rsvc *registry.Service
+
+	ready *admin.Readiness // ready after registered
 }
 
 // NewServer create Server
 func NewServer(registry registry.Registry) *Server {
 	s := &Server{
 		registry: registry,
+		ready:    admin.NewReadiness("grpc server"),
 	}
 
 	tracer := opentracing.GlobalTracer()
 	grpcOptions := grpc_go.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
+		metadataInterceptor(),
 		trace.ServerInterceptor(tracer),
-		grpc_prometheus.UnaryServerInterceptor))
+		grpc_prometheus.UnaryServerInterceptor,
+		status.ServerInterceptor())) // ecode -> grpc status, 放在最内层以便监控拿到转换后的code
 
-	s.srv = grpc_go.NewServer(grpcOptions)
+	s.srv = grpc_go.NewServer(grpcOptions, grpc_go.StatsHandler(accessLogHandler{}))
 
 	return s
 }
@@ -64,6 +71,8 @@ func (s *Server) Server() *grpc_go.Server {
 // Shutdown .
 func (s *Server) Shutdown() error {
 
+	s.ready.Set(false)
+
 	if s.registry != nil {
 		s.registry.Deregister(s.rsvc)
 	}
@@ -119,6 +128,7 @@ func (s *Server) Start(serviceName string) error {
 		return err
 	}
 
+	s.ready.Set(true)
 	return nil
 }
 
@@ -136,6 +146,11 @@ func (s *Server) register(serviceName string, addr string) error {
 	node.Metadata["transport"] = s.String()
 	node.Metadata["protocol"] = "grpc"
 
+	// prometheus 通过注册中心发现 metrics 地址
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		admin.Metadata(node.Metadata, host)
+	}
+
 	service := &registry.Service{
 		Name:      serviceName,
 		Version:   "latest",
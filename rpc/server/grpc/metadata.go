@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/robert-pkg/micro-go/rpc"
+	rpc_metadata "github.com/robert-pkg/micro-go/rpc/metadata"
+
+	grpc_go "google.golang.org/grpc"
+	grpc_metadata "google.golang.org/grpc/metadata"
+)
+
+// 需要从 grpc metadata 带到 rpc metadata 中的key
+var propagateKeys = []string{
+	rpc.RequestID,
+	rpc.UserID,
+	rpc.DeviceType,
+	rpc.SkipTrace,
+	rpc.Locale,
+	rpc.AcceptLanguage,
+}
+
+// metadataInterceptor copy request metadata from grpc incoming metadata into rpc metadata,
+// so log.XxxContext gets request id, and downstream calls propagate them.
+func metadataInterceptor() grpc_go.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc_go.UnaryServerInfo, handler grpc_go.UnaryHandler) (resp interface{}, err error) {
+
+		md, ok := grpc_metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		patch := make(rpc_metadata.Metadata, len(propagateKeys))
+		for _, key := range propagateKeys {
+			// grpc metadata 中的key都是小写
+			if values := md.Get(key); len(values) > 0 {
+				patch[key] = values[0]
+			}
+		}
+
+		if len(patch) > 0 {
+			ctx = rpc_metadata.MergeContext(ctx, patch, false)
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/robert-pkg/micro-go/log"
 	"github.com/robert-pkg/micro-go/registry"
+	"github.com/robert-pkg/micro-go/rpc/admin"
 	"github.com/robert-pkg/micro-go/trace"
 	"github.com/robert-pkg/micro-go/utils"
 )
@@ -28,6 +30,8 @@ type Server struct {
 
 	// registry service instance
 	rsvc *registry.Service
+
+	ready *admin.Readiness // ready after registered
 }
 
 // NewServer create Server
@@ -35,6 +39,7 @@ func NewServer(registry registry.Registry, serviceName string) *Server {
 	s := &Server{
 		registry:    registry,
 		serviceName: serviceName,
+		ready:       admin.NewReadiness("http server"),
 	}
 
 	if len(serviceName) > 0 {
@@ -48,8 +53,8 @@ func NewServer(registry registry.Registry, serviceName string) *Server {
 	engine.Use(gin.Recovery())
 
 	if true {
-		m := make([]gin.HandlerFunc, 0, 2)
-		m = append(m, logger())
+		m := make([]gin.HandlerFunc, 0, 4)
+		m = append(m, logger(), metrics(), accessLog())
 
 		tracer := opentracing.GlobalTracer()
 		if tracer != nil {
@@ -67,6 +72,8 @@ func NewServer(registry registry.Registry, serviceName string) *Server {
 // Shutdown .
 func (s *Server) Shutdown() error {
 
+	s.ready.Set(false)
+
 	if s.registry != nil {
 		s.registry.Deregister(s.rsvc)
 	}
@@ -124,6 +131,7 @@ func (s *Server) Start() error {
 		return err
 	}
 
+	s.ready.Set(true)
 	return nil
 }
 
@@ -141,6 +149,11 @@ func (s *Server) register(addr string) error {
 	node.Metadata["transport"] = s.String()
 	node.Metadata["protocol"] = "http"
 
+	// prometheus 通过注册中心发现 metrics 地址
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		admin.Metadata(node.Metadata, host)
+	}
+
 	service := &registry.Service{
 		Name:      s.serviceName,
 		Version:   "latest",
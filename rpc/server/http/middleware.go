@@ -1,9 +1,15 @@
 package http
 
 import (
+	"fmt"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/robert-pkg/micro-go/log"
 	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/accesslog"
+	"github.com/robert-pkg/micro-go/rpc/metadata"
+	rpc_metrics "github.com/robert-pkg/micro-go/rpc/metrics"
 )
 
 func logger() gin.HandlerFunc {
@@ -20,12 +26,85 @@ func logger() gin.HandlerFunc {
 		}
 
 		if len(reqID) > 0 {
-			// 将 requestID 注入到log中
-			log.SetReqMetaForGoroutine(reqID)
-			defer log.DeleteMetaForGoroutine()
+			// 将 requestID 注入到 request 的 context 中, log.XxxContext 会带上
+			ctx := metadata.Set(c.Request.Context(), rpc.RequestID, reqID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+
+}
+
+// metrics RED metrics, 按 route 模板而不是原始 path 统计
+func metrics() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+		done := rpc_metrics.HTTPServerStart(c.Request.Method, c.FullPath())
+
+		// gin.Recovery 在外层, panic 时这里还没有写入 500, 记录后继续 panic
+		defer func() {
+			if err := recover(); err != nil {
+				done(http.StatusInternalServerError)
+				panic(err)
+			}
+		}()
+
+		c.Next()
+		done(c.Writer.Status())
+	}
+}
+
+// accessLog 访问日志, 见 accesslog.Init
+func accessLog() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		if !accesslog.Enabled() {
+			c.Next()
+			return
 		}
 
+		start := time.Now()
+
+		// gin.Recovery 在外层, panic 时也要记录, 状态为 500, 记录后继续 panic
+		defer func() {
+			if err := recover(); err != nil {
+				writeAccessLog(c, start, http.StatusInternalServerError, fmt.Sprint(err))
+				panic(err)
+			}
+		}()
+
 		c.Next()
+
+		var errStr string
+		if err := c.Errors.Last(); err != nil {
+			errStr = err.Error()
+		}
+		writeAccessLog(c, start, c.Writer.Status(), errStr)
+	}
+}
+
+func writeAccessLog(c *gin.Context, start time.Time, status int, errStr string) {
+	e := &accesslog.Entry{
+		Time:      start,
+		Protocol:  "http",
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Status:    status,
+		Latency:   time.Since(start),
+		Peer:      c.ClientIP(),
+		ReqSize:   c.Request.ContentLength,
+		RequestID: c.Request.Header.Get(rpc.RequestID),
+		UserID:    c.Request.Header.Get(rpc.UserID),
+		Err:       errStr,
+	}
+	if size := c.Writer.Size(); size > 0 {
+		e.RespSize = int64(size)
+	}
+	if e.ReqSize < 0 {
+		e.ReqSize = 0
 	}
 
+	accesslog.Write(e)
 }
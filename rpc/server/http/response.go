@@ -0,0 +1,40 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robert-pkg/micro-go/ecode"
+	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/rpc/status"
+)
+
+// JSON write data or err to client in standard envelope.
+// {code, message, data, request_id}
+func JSON(c *gin.Context, data interface{}, err error) {
+	ec := ecode.Cause(err)
+	resp := status.NewResponse(c.GetHeader(rpc.RequestID), data, err, locales(c)...)
+
+	c.Header(rpc.ErrorCode, strconv.FormatInt(int64(ec.Code()), 10))
+	c.JSON(status.HTTPStatus(ec), resp)
+}
+
+// Success write data to client.
+func Success(c *gin.Context, data interface{}) {
+	JSON(c, data, nil)
+}
+
+// Error write err to client, then abort the rest handlers.
+func Error(c *gin.Context, err error) {
+	JSON(c, nil, err)
+	c.Abort()
+}
+
+// locales return locales of request, custom locale comes first.
+func locales(c *gin.Context) []string {
+	var list []string
+	if locale := c.GetHeader(rpc.Locale); len(locale) > 0 {
+		list = append(list, locale)
+	}
+	return append(list, ecode.ParseAcceptLanguage(c.GetHeader(rpc.AcceptLanguage))...)
+}
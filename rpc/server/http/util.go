@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/robert-pkg/micro-go/log"
+	"github.com/robert-pkg/micro-go/trace"
 
 	"github.com/robert-pkg/micro-go/rpc/metadata"
 	//"google.golang.org/grpc/metadata"
@@ -22,15 +23,13 @@ func GetContext(c *gin.Context) (context.Context, context.CancelFunc) {
 		if len(v) >= 1 {
 			key := strings.ToLower(k)
 			md[key] = v[0]
-			log.Info("FillContext", "k", k, "key", key, "v", v)
+			log.Info("FillContext", "k", k, "key", key, "v", log.RedactHeader(k, v))
 		}
 	}
 
-	if parentSpanContext, ok := c.Get("ParentSpanContext"); ok {
-		ctx = context.WithValue(ctx, "ParentSpanContext", parentSpanContext)
+	if span := trace.SpanFromContext(c.Request.Context()); span != nil {
+		ctx = trace.ContextWithSpan(ctx, span)
 	}
 
-	// c.Set("ParentSpanContext", parentSpan.Context())
-
 	return metadata.NewContext(ctx, md), cancel
 }
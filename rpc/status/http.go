@@ -0,0 +1,165 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/robert-pkg/micro-go/ecode"
+	"github.com/robert-pkg/micro-go/rpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Response standard http response envelope.
+type Response struct {
+	Code      int32             `json:"code"`
+	Message   string            `json:"message"`
+	Data      interface{}       `json:"data,omitempty"`
+	Details   []json.RawMessage `json:"details,omitempty"` // google.protobuf.Any in json, e.g. {"@type": "type.googleapis.com/google.rpc.BadRequest", ...}
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// rawResponse used for decoding, keep data as raw json.
+type rawResponse struct {
+	Code      int32             `json:"code"`
+	Message   string            `json:"message"`
+	Data      json.RawMessage   `json:"data,omitempty"`
+	Details   []json.RawMessage `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// NewResponse build envelope from data or err, message is localized by locales.
+func NewResponse(requestID string, data interface{}, err error, locales ...string) *Response {
+	ec := ecode.Cause(err)
+	resp := &Response{
+		Code:      ec.Code(),
+		Message:   ecode.LocaleMessage(ec, locales...),
+		RequestID: requestID,
+	}
+
+	if err == nil {
+		resp.Data = data
+	} else {
+		for _, d := range ec.Details() {
+			// debug info 不返回给调用方
+			if _, ok := d.(*errdetails.DebugInfo); ok {
+				continue
+			}
+			if pd, ok := d.(proto.Message); ok {
+				if raw, err := encodeDetail(pd); err == nil {
+					resp.Details = append(resp.Details, raw)
+				}
+			}
+		}
+	}
+
+	return resp
+}
+
+// HTTPStatus ecode -> http status
+func HTTPStatus(ec ecode.Codes) int {
+	switch ec.Code() {
+	case ecode.OkCode:
+		return http.StatusOK
+	case ecode.ErrParamCode, ecode.ErrDeviceTypeCode:
+		return http.StatusBadRequest
+	case ecode.ErrNoUserIDCode:
+		return http.StatusUnauthorized
+	case ecode.ErrSystemBusyCode:
+		return http.StatusServiceUnavailable
+	case ecode.ErrServerCode:
+		return http.StatusInternalServerError
+	}
+
+	// 业务错误码, 由 envelope 中的 code 表示
+	return http.StatusOK
+}
+
+// fromHTTPStatus http status -> ecode, used when response is not an envelope.
+func fromHTTPStatus(statusCode int) ecode.Codes {
+	switch statusCode {
+	case http.StatusOK:
+		return ecode.OK
+	case http.StatusBadRequest:
+		return ecode.ErrParam
+	case http.StatusUnauthorized:
+		return ecode.ErrNoUserID
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		return ecode.ErrSystemBusy
+	}
+
+	return ecode.ErrServer
+}
+
+// DecodeResponse is the client side of NewResponse, it unwraps the envelope of a http response.
+// it returns the raw json of data ("null" if absent) on success, or ecode.Codes carrying code and message of the envelope.
+// response without rpc.ErrorCode header is not an envelope: body is returned as is for 200,
+// other status codes are mapped to ecode by http status.
+func DecodeResponse(statusCode int, header http.Header, body []byte) ([]byte, error) {
+
+	if len(header.Get(rpc.ErrorCode)) <= 0 {
+		// 非标准 envelope
+		if statusCode != http.StatusOK {
+			return nil, errors.Wrapf(fromHTTPStatus(statusCode), "error: %s", string(body))
+		}
+		return body, nil
+	}
+
+	var resp rawResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrapf(fromHTTPStatus(statusCode), "decode response fail: %s", err)
+	}
+
+	if resp.Code != ecode.OkCode {
+		st := ecode.Error(ecode.Int32(resp.Code), resp.Message)
+		if details := decodeDetails(resp.Details); len(details) > 0 {
+			st = st.WithDetails(details...)
+		}
+		return nil, st
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, fromHTTPStatus(statusCode)
+	}
+
+	if len(resp.Data) <= 0 {
+		return []byte("null"), nil
+	}
+
+	return resp.Data, nil
+}
+
+// encodeDetail encode detail as google.protobuf.Any in json, keeping its type
+func encodeDetail(d proto.Message) (json.RawMessage, error) {
+	detail, err := anypb.New(d)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := protojson.Marshal(detail)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
+
+// decodeDetails decode details encoded by encodeDetail, unknown types are skipped
+func decodeDetails(raws []json.RawMessage) []proto.Message {
+	details := make([]proto.Message, 0, len(raws))
+	for _, raw := range raws {
+		var detail anypb.Any
+		if err := protojson.Unmarshal(raw, &detail); err != nil {
+			continue
+		}
+
+		m, err := detail.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+		details = append(details, m)
+	}
+	return details
+}
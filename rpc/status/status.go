@@ -0,0 +1,174 @@
+// Package status converts ecode.Codes to grpc status and back,
+// so business error codes survive service hops.
+package status
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/robert-pkg/micro-go/ecode"
+	"github.com/robert-pkg/micro-go/rpc"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	// ecodeDomain ErrorInfo.Domain used to mark details carrying an ecode
+	ecodeDomain = "micro-go.ecode"
+
+	metaCode    = "code"
+	metaMessage = "message"
+)
+
+// ServerInterceptor convert ecode.Codes returned by handler into grpc status.
+func ServerInterceptor() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+		resp, err = handler(ctx, req)
+		if err != nil {
+			return resp, FromError(err, incomingLocales(ctx)...).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// incomingLocales return locales carried by grpc incoming metadata.
+func incomingLocales(ctx context.Context) []string {
+	md, ok := grpc_metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var locales []string
+	locales = append(locales, md.Get(rpc.Locale)...)
+	for _, al := range md.Get(rpc.AcceptLanguage) {
+		locales = append(locales, ecode.ParseAcceptLanguage(al)...)
+	}
+	return locales
+}
+
+// FromError convert error to grpc status, message is localized by locales.
+// the code and message of ecode are carried in status details.
+func FromError(err error, locales ...string) *grpc_status.Status {
+	if err == nil {
+		return nil
+	}
+
+	// 已经是 grpc status 了，直接返回
+	if st, ok := err.(interface{ GRPCStatus() *grpc_status.Status }); ok {
+		return st.GRPCStatus()
+	}
+
+	ec := ecode.Cause(err)
+	message := ecode.LocaleMessage(ec, locales...)
+	st := grpc_status.New(toGRPCCode(ec), message)
+
+	info := &errdetails.ErrorInfo{
+		Reason: strconv.FormatInt(int64(ec.Code()), 10),
+		Domain: ecodeDomain,
+		Metadata: map[string]string{
+			metaCode:    strconv.FormatInt(int64(ec.Code()), 10),
+			metaMessage: message,
+		},
+	}
+
+	details := []protoadapt.MessageV1{info}
+	for _, d := range ec.Details() {
+		if pd, ok := d.(proto.Message); ok {
+			details = append(details, protoadapt.MessageV1Of(pd))
+		}
+	}
+
+	if dst, err := st.WithDetails(details...); err == nil {
+		return dst
+	}
+
+	return st
+}
+
+// ToEcode convert error returned by grpc call into ecode.Codes.
+// err is kept as cause of the returned *ecode.Status, e.g. for codes.DeadlineExceeded mapped to ErrServer.
+func ToEcode(err error) ecode.Codes {
+	if err == nil {
+		return ecode.OK
+	}
+
+	st, ok := grpc_status.FromError(err)
+	if !ok {
+		return ecode.Cause(err)
+	}
+
+	var ec *ecode.Status
+	details := make([]proto.Message, 0, len(st.Details()))
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if ok && info.GetDomain() == ecodeDomain && ec == nil {
+			code, err := strconv.ParseInt(info.GetMetadata()[metaCode], 10, 32)
+			if err == nil {
+				ec = ecode.Error(ecode.Int32(int32(code)), info.GetMetadata()[metaMessage])
+				continue
+			}
+		}
+
+		// 其余 details 原样带回
+		if pd, ok := detail.(proto.Message); ok {
+			details = append(details, pd)
+		}
+	}
+
+	if ec == nil {
+		if st.Code() == codes.OK {
+			return ecode.OK
+		}
+		return ecode.Wrap(err, fromGRPCCode(st.Code()))
+	}
+
+	if len(details) > 0 {
+		ec = ec.WithDetails(details...)
+	}
+
+	return ec.WithCause(err)
+}
+
+// toGRPCCode ecode -> grpc code
+func toGRPCCode(ec ecode.Codes) codes.Code {
+	switch ec.Code() {
+	case ecode.OkCode:
+		return codes.OK
+	case ecode.ErrParamCode, ecode.ErrDeviceTypeCode:
+		return codes.InvalidArgument
+	case ecode.ErrNoUserIDCode:
+		return codes.Unauthenticated
+	case ecode.ErrSystemBusyCode:
+		return codes.Unavailable
+	case ecode.ErrServerCode:
+		return codes.Internal
+	}
+
+	// 业务错误码
+	return codes.Unknown
+}
+
+// fromGRPCCode grpc code -> ecode, used when status carries no ecode details.
+func fromGRPCCode(code codes.Code) ecode.Code {
+	switch code {
+	case codes.OK:
+		return ecode.OK
+	case codes.InvalidArgument:
+		return ecode.ErrParam
+	case codes.Unauthenticated:
+		return ecode.ErrNoUserID
+	case codes.Unavailable, codes.ResourceExhausted:
+		return ecode.ErrSystemBusy
+	}
+
+	return ecode.ErrServer
+}
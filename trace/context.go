@@ -0,0 +1,29 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// ContextWithSpan return a ctx carrying span, it's the same as opentracing.ContextWithSpan,
+// so opentracing.StartSpanFromContext etc. create child spans of it.
+func ContextWithSpan(ctx context.Context, span opentracing.Span) context.Context {
+	return opentracing.ContextWithSpan(ctx, span)
+}
+
+// SpanFromContext return span carried by ctx, nil if not exist.
+func SpanFromContext(ctx context.Context) opentracing.Span {
+	if ctx == nil {
+		return nil
+	}
+	return opentracing.SpanFromContext(ctx)
+}
+
+// SpanContextFromContext return context of span carried by ctx, nil if not exist.
+func SpanContextFromContext(ctx context.Context) opentracing.SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context()
+	}
+	return nil
+}
@@ -1,9 +1,14 @@
 package trace
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/robert-pkg/micro-go/log"
+	"github.com/robert-pkg/micro-go/rpc"
 )
 
 // SetUpTraceForGinServer .
@@ -11,29 +16,70 @@ func SetUpTraceForGinServer() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 
+		if isTrue(c.GetHeader(rpc.SkipTrace)) {
+			// handler 直接使用 c.Request.Context() 调用下游时也不创建 span
+			c.Request = c.Request.WithContext(WithSkip(c.Request.Context()))
+			c.Next()
+			return
+		}
+
 		tracer := opentracing.GlobalTracer()
-		if tracer != nil {
+		if tracer == nil {
+			c.Next()
+			return
+		}
 
-			spCtx, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(c.Request.Header))
-			if err != nil {
-				//parentSpan = tracer.StartSpan(c.Request.URL.Path)
-				//defer parentSpan.Finish()
-			} else {
-				span := opentracing.StartSpan(
-					c.Request.URL.Path,
-					opentracing.ChildOf(spCtx),
-					opentracing.Tag{Key: string(ext.Component), Value: "HTTP"},
-					ext.SpanKindRPCServer,
-				)
-				defer span.Finish()
+		spCtx, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(c.Request.Header))
+		if err != nil && err != opentracing.ErrSpanContextNotFound {
+			log.ErrorContext(c.Request.Context(), "extract from header fail", "err", err)
+		}
 
-				c.Set("Tracer", tracer)
-				c.Set("ParentSpanContext", span.Context())
-			}
+		// 没有 parent 时 spCtx 为 nil, 开始新的 trace, 是否采样由 sampler 决定
+		opts := []opentracing.StartSpanOption{
+			ext.RPCServerOption(spCtx),
+			opentracing.Tag{Key: string(ext.Component), Value: "HTTP"},
+			ext.SpanKindRPCServer,
+		}
+		if tag, ok := samplingPriority(c.GetHeader); ok {
+			opts = append(opts, tag)
+		}
 
+		span := tracer.StartSpan(c.Request.URL.Path, opts...)
+		defer span.Finish()
+
+		ext.HTTPMethod.Set(span, c.Request.Method)
+		// 不记录 query, 其中可能有 token 等敏感信息
+		ext.HTTPUrl.Set(span, c.Request.URL.Path)
+		if reqID := c.GetHeader(rpc.RequestID); len(reqID) > 0 {
+			span.SetTag(rpc.RequestID, reqID)
 		}
 
+		c.Set("Tracer", tracer)
+		// handler 通过 c.Request.Context() 拿到 span, 见 trace.SpanFromContext
+		c.Request = c.Request.WithContext(ContextWithSpan(c.Request.Context(), span))
+
 		c.Next()
+
+		status := c.Writer.Status()
+		ext.HTTPStatusCode.Set(span, uint16(status))
+
+		// 见 rpc/server/http.JSON
+		if code, err := strconv.ParseInt(c.Writer.Header().Get(rpc.ErrorCode), 10, 32); err == nil && code != 0 {
+			span.SetTag(TagErrorCode, int32(code))
+			ext.Error.Set(span, true)
+		}
+
+		if status >= 500 {
+			ext.Error.Set(span, true)
+		}
+
+		for _, e := range c.Errors {
+			SetError(span, e.Err)
+		}
+
+		if len(c.Errors) <= 0 && status >= 500 {
+			SetError(span, fmt.Errorf("http status: %d", status))
+		}
 	}
 
 }
@@ -16,25 +16,14 @@ func ClientInterceptor(tracer opentracing.Tracer) grpc.UnaryClientInterceptor {
 
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 
-		if tracer == nil {
+		if tracer == nil || IsSkipped(ctx) {
 			// no tracer, so invoke directly
 			return invoker(ctx, method, req, reply, cc, opts...)
 		}
 
-		var parentCtx opentracing.SpanContext
-		if true {
-			if pc := ctx.Value("ParentSpanContext"); pc != nil {
-
-				if realPC, ok := pc.(opentracing.SpanContext); ok {
-					parentCtx = realPC
-				}
-
-			}
-		}
-
 		span := tracer.StartSpan(
 			method,
-			opentracing.ChildOf(parentCtx), // can be nil
+			opentracing.ChildOf(SpanContextFromContext(ctx)), // can be nil
 			opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
 			ext.SpanKindRPCClient,
 		)
@@ -7,8 +7,8 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
-	opentracelog "github.com/opentracing/opentracing-go/log"
 	"github.com/robert-pkg/micro-go/log"
+	"github.com/robert-pkg/micro-go/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
@@ -18,7 +18,7 @@ func ServerInterceptor(tracer opentracing.Tracer) grpc.UnaryServerInterceptor {
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 
-		if tracer == nil {
+		if tracer == nil || IsSkipped(ctx) {
 			return handler(ctx, req)
 		}
 
@@ -31,31 +31,44 @@ func ServerInterceptor(tracer opentracing.Tracer) grpc.UnaryServerInterceptor {
 		//从metadata中取出最终数据，并创建出span对象
 		spanContext, err := tracer.Extract(opentracing.TextMap, MDReaderWriter{md})
 		if err != nil && err != opentracing.ErrSpanContextNotFound {
-			log.Error("extract from metadata fail", "err", err)
-		} else {
-			// 生成 server 端的span
-			span := tracer.StartSpan(
-				info.FullMethod,
-				ext.RPCServerOption(spanContext),
-				opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
-				ext.SpanKindRPCServer,
-			)
-			defer span.Finish()
-
-			ctx = context.WithValue(ctx, "ParentSpanContext", span.Context())
-
-			// 将requestID注入到日志中
-			requestIDs := md.Get(rpc.RequestID)
-			if len(requestIDs) >= 1 {
-
-				// 将 requestID 注入到log中
-				log.SetReqMetaForGoroutine(requestIDs[0])
-				span.LogFields(opentracelog.String(rpc.RequestID, requestIDs[0]))
-
-				defer log.DeleteMetaForGoroutine()
+			log.ErrorContext(ctx, "extract from metadata fail", "err", err)
+		}
+
+		// 生成 server 端的span, 没有 parent 时开始新的 trace, 是否采样由 sampler 决定
+		opts := []opentracing.StartSpanOption{
+			ext.RPCServerOption(spanContext),
+			opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
+			ext.SpanKindRPCServer,
+		}
+		if tag, ok := samplingPriority(func(key string) string {
+			if v := md.Get(key); len(v) > 0 {
+				return v[0]
 			}
+			return ""
+		}); ok {
+			opts = append(opts, tag)
+		}
+
+		span := tracer.StartSpan(info.FullMethod, opts...)
+		defer span.Finish()
+
+		ctx = ContextWithSpan(ctx, span)
+
+		// 将requestID记录到span中
+		requestIDs := md.Get(rpc.RequestID)
+		if len(requestIDs) >= 1 {
+			span.SetTag(rpc.RequestID, requestIDs[0])
+		}
+
+		resp, err = handler(ctx, req)
+
+		// err 可能是 ecode, 也可能已经被转换成 grpc status, 两者都能拿到 code
+		span.SetTag(TagGRPCCode, status.FromError(err).Code().String())
+		if err != nil {
+			span.SetTag(TagErrorCode, status.ToEcode(err).Code())
+			SetError(span, err)
 		}
 
-		return handler(ctx, req)
+		return resp, err
 	}
 }
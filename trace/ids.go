@@ -0,0 +1,34 @@
+package trace
+
+import (
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// SpanContextIDs implemented by span contexts of tracers other than jaeger, e.g. otel-trace,
+// so that trace id can be got without knowing the tracer.
+type SpanContextIDs interface {
+	TraceIDString() string
+	SpanIDString() string
+}
+
+// IDs return trace id and span id of sc, ok is false if sc is nil, invalid or unknown.
+func IDs(sc opentracing.SpanContext) (traceID string, spanID string, ok bool) {
+	switch c := sc.(type) {
+	case jaeger.SpanContext:
+		if !c.IsValid() {
+			return "", "", false
+		}
+		return c.TraceID().String(), c.SpanID().String(), true
+	case SpanContextIDs:
+		traceID, spanID = c.TraceIDString(), c.SpanIDString()
+		// otel 的无效 id 全为0
+		if len(strings.Trim(traceID, "0")) <= 0 {
+			return "", "", false
+		}
+		return traceID, spanID, true
+	}
+	return "", "", false
+}
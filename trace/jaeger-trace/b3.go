@@ -0,0 +1,125 @@
+package jaeger
+
+import (
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+
+	jaeger_go "github.com/uber/jaeger-client-go"
+	jaeger_zipkin "github.com/uber/jaeger-client-go/zipkin"
+)
+
+// propagation formats
+const (
+	PropagationJaeger   = "jaeger"    // uber-trace-id
+	PropagationB3       = "b3"        // zipkin b3 multi headers: x-b3-traceid, x-b3-spanid ...
+	PropagationB3Single = "b3-single" // zipkin b3 single header: b3: {traceid}-{spanid}-{sampled}-{parentspanid}
+)
+
+const b3SingleHeader = "b3"
+
+// B3Propagator inject b3 single or multi headers, extract any of b3 single, b3 multi and jaeger headers,
+// so that services using different formats can still call each other.
+// it works on grpc metadata (trace.MDReaderWriter) and http headers.
+type B3Propagator struct {
+	single bool
+	multi  jaeger_zipkin.Propagator
+	jaeger *jaeger_go.TextMapPropagator
+}
+
+// NewB3Propagator single is true to inject b3 single header, otherwise multi headers.
+func NewB3Propagator(single bool) *B3Propagator {
+	return &B3Propagator{
+		single: single,
+		multi:  jaeger_zipkin.NewZipkinB3HTTPHeaderPropagator(),
+		jaeger: jaeger_go.NewHTTPHeaderPropagator(new(jaeger_go.HeadersConfig).ApplyDefaults(), *jaeger_go.NewNullMetrics()),
+	}
+}
+
+// Inject implements jaeger.Injector
+func (p *B3Propagator) Inject(sc jaeger_go.SpanContext, abstractCarrier interface{}) error {
+	if !p.single {
+		return p.multi.Inject(sc, abstractCarrier)
+	}
+
+	w, ok := abstractCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+
+	sampled := "0"
+	if sc.IsDebug() {
+		sampled = "d"
+	} else if sc.IsSampled() {
+		sampled = "1"
+	}
+
+	value := sc.TraceID().String() + "-" + sc.SpanID().String() + "-" + sampled
+	if sc.ParentID() != 0 {
+		value += "-" + sc.ParentID().String()
+	}
+	w.Set(b3SingleHeader, value)
+	return nil
+}
+
+// Extract implements jaeger.Extractor
+func (p *B3Propagator) Extract(abstractCarrier interface{}) (jaeger_go.SpanContext, error) {
+	r, ok := abstractCarrier.(opentracing.TextMapReader)
+	if !ok {
+		return jaeger_go.SpanContext{}, opentracing.ErrInvalidCarrier
+	}
+
+	var single string
+	r.ForeachKey(func(key, val string) error {
+		if strings.ToLower(key) == b3SingleHeader {
+			single = val
+		}
+		return nil
+	})
+
+	if len(single) > 0 {
+		if sc, ok := parseB3Single(single); ok {
+			return sc, nil
+		}
+	}
+
+	sc, err := p.multi.Extract(abstractCarrier)
+	if err == nil {
+		return sc, nil
+	}
+
+	return p.jaeger.Extract(abstractCarrier)
+}
+
+// parseB3Single parse {traceid}-{spanid}[-{sampled}[-{parentspanid}]],
+// a value of only sampling state (e.g. "0") has no span context.
+func parseB3Single(value string) (jaeger_go.SpanContext, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return jaeger_go.SpanContext{}, false
+	}
+
+	traceID, err := jaeger_go.TraceIDFromString(parts[0])
+	if err != nil || !traceID.IsValid() {
+		return jaeger_go.SpanContext{}, false
+	}
+
+	spanID, err := jaeger_go.SpanIDFromString(parts[1])
+	if err != nil {
+		return jaeger_go.SpanContext{}, false
+	}
+
+	sampled := false
+	if len(parts) >= 3 {
+		sampled = parts[2] == "1" || parts[2] == "d"
+	}
+
+	var parentID jaeger_go.SpanID
+	if len(parts) == 4 {
+		if parentID, err = jaeger_go.SpanIDFromString(parts[3]); err != nil {
+			return jaeger_go.SpanContext{}, false
+		}
+	}
+
+	return jaeger_go.NewSpanContext(traceID, spanID, parentID, sampled, nil), true
+}
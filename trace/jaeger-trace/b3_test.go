@@ -0,0 +1,137 @@
+package jaeger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	jaeger_go "github.com/uber/jaeger-client-go"
+)
+
+func TestB3RoundTrip(t *testing.T) {
+	traceID := jaeger_go.TraceID{High: 0x463ac35c9f6413ad, Low: 0x48485a3953bb6124}
+
+	tests := []struct {
+		name   string
+		single bool
+		sc     jaeger_go.SpanContext
+		header string // header must be injected
+	}{
+		{
+			name:   "single",
+			single: true,
+			sc:     jaeger_go.NewSpanContext(traceID, 0xa2fb4a1d1a96d312, 0x0020000000000001, true, nil),
+			header: "B3",
+		},
+		{
+			name:   "single not sampled without parent",
+			single: true,
+			sc:     jaeger_go.NewSpanContext(jaeger_go.TraceID{Low: 1}, 2, 0, false, nil),
+			header: "B3",
+		},
+		{
+			name:   "multi",
+			sc:     jaeger_go.NewSpanContext(traceID, 0xa2fb4a1d1a96d312, 0x0020000000000001, true, nil),
+			header: "X-B3-Traceid",
+		},
+		{
+			name:   "multi not sampled",
+			sc:     jaeger_go.NewSpanContext(jaeger_go.TraceID{Low: 1}, 2, 0, false, nil),
+			header: "X-B3-Traceid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewB3Propagator(tt.single)
+
+			h := http.Header{}
+			if err := p.Inject(tt.sc, opentracing.HTTPHeadersCarrier(h)); err != nil {
+				t.Fatal(err)
+			}
+			if len(h.Get(tt.header)) <= 0 {
+				t.Fatalf("%s not injected: %v", tt.header, h)
+			}
+
+			// 任意一种格式注入的 header 都能被另一种配置提取
+			for _, single := range []bool{true, false} {
+				sc, err := NewB3Propagator(single).Extract(opentracing.HTTPHeadersCarrier(h))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if sc.TraceID() != tt.sc.TraceID() || sc.SpanID() != tt.sc.SpanID() ||
+					sc.ParentID() != tt.sc.ParentID() || sc.IsSampled() != tt.sc.IsSampled() {
+					t.Errorf("extract by single=%v: got %v, want %v", single, sc, tt.sc)
+				}
+			}
+		})
+	}
+}
+
+func TestB3Extract(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		want    string // trace id, empty means error
+		sampled bool
+	}{
+		{
+			name:    "single debug",
+			header:  http.Header{"B3": {"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-d"}},
+			want:    "80f198ee56343ba864fe8b2a57d3eff7",
+			sampled: true,
+		},
+		{
+			name: "single wins over multi",
+			header: http.Header{
+				"B3":           {"0000000000000001-0000000000000002-0"},
+				"X-B3-Traceid": {"3"},
+				"X-B3-Spanid":  {"4"},
+			},
+			want: "0000000000000001",
+		},
+		{
+			name: "single of only sampling state falls back to multi",
+			header: http.Header{
+				"B3":           {"0"},
+				"X-B3-Traceid": {"3"},
+				"X-B3-Spanid":  {"4"},
+			},
+			want: "0000000000000003",
+		},
+		{
+			name:    "jaeger",
+			header:  http.Header{"Uber-Trace-Id": {"5:6:0:1"}},
+			want:    "0000000000000005",
+			sampled: true,
+		},
+		{
+			name:   "invalid single",
+			header: http.Header{"B3": {"x-y"}},
+		},
+		{
+			name:   "none",
+			header: http.Header{},
+		},
+	}
+
+	p := NewB3Propagator(true)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := p.Extract(opentracing.HTTPHeadersCarrier(tt.header))
+			if len(tt.want) <= 0 {
+				if err == nil {
+					t.Errorf("got %v, want error", sc)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sc.TraceID().String() != tt.want || sc.IsSampled() != tt.sampled {
+				t.Errorf("got %v, want trace id %s sampled %v", sc, tt.want, tt.sampled)
+			}
+		})
+	}
+}
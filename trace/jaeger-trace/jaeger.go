@@ -2,12 +2,15 @@ package jaeger
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 
+	jaeger_go "github.com/uber/jaeger-client-go"
 	jaeger_cfg "github.com/uber/jaeger-client-go/config"
+	jaeger_zipkin_transport "github.com/uber/jaeger-client-go/transport/zipkin"
 )
 
 // Config .
@@ -18,8 +21,26 @@ type Config struct {
 	BufferFlushInterval time.Duration `yaml:"buffer_flush_interval"` // second
 	QueueSize           int           `yaml:"queue_size"`            // span queue size in memory
 	AgentAddr           string        `yaml:"agent_addr"`
+
+	// Propagation jaeger(default), b3, b3-single, 使用 b3 时也能解析 jaeger 格式
+	Propagation string `yaml:"propagation"`
+	// Reporter jaeger(default, 上报到 AgentAddr), zipkin(上报到 CollectorEndpoint)
+	Reporter string `yaml:"reporter"`
+	// CollectorEndpoint zipkin collector, e.g. http://127.0.0.1:9411/api/v1/spans
+	CollectorEndpoint string `yaml:"collector_endpoint"`
+
+	// Operations 按 operation 设置采样, 没有匹配的使用 Type, e.g. 健康检查 const 0, 慢接口 probabilistic 1
+	Operations []OperationSampling `yaml:"operations"`
+	// SampleOnError 没有被采样的 trace 出错时改为采样, 只对本进程内的 span 有效, 已经传给下游的仍是不采样
+	SampleOnError bool `yaml:"sample_on_error"`
 }
 
+// reporters
+const (
+	ReporterJaeger = "jaeger"
+	ReporterZipkin = "zipkin"
+)
+
 // NewTracer for current service
 // "127.0.0.1:6831"
 func NewTracer(serviceName string, c *Config) (tracer opentracing.Tracer, closer io.Closer, err error) {
@@ -41,8 +62,79 @@ func NewTracer(serviceName string, c *Config) (tracer opentracing.Tracer, closer
 		},
 	}
 
+	opts := []jaeger_cfg.Option{jaeger_cfg.Logger(logger{})}
+
+	switch c.Propagation {
+	case "", PropagationJaeger:
+	case PropagationB3, PropagationB3Single:
+		p := NewB3Propagator(c.Propagation == PropagationB3Single)
+		opts = append(opts,
+			jaeger_cfg.Injector(opentracing.TextMap, p),
+			jaeger_cfg.Extractor(opentracing.TextMap, p),
+			jaeger_cfg.Injector(opentracing.HTTPHeaders, p),
+			jaeger_cfg.Extractor(opentracing.HTTPHeaders, p),
+			// zipkin 的 client, server 共用一个 span id
+			jaeger_cfg.ZipkinSharedRPCSpan(true),
+		)
+	default:
+		return nil, nil, fmt.Errorf("unknown propagation: '%s'", c.Propagation)
+	}
+
+	var reporter jaeger_go.Reporter
+	switch c.Reporter {
+	case "", ReporterJaeger:
+		if c.SampleOnError {
+			// sampler 需要上报缓存的 span, 由这里创建 reporter
+			reporter, err = jcfg.Reporter.NewReporter(serviceName, jaeger_go.NewNullMetrics(), logger{})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	case ReporterZipkin:
+		if len(c.CollectorEndpoint) <= 0 {
+			return nil, nil, errors.New("collector_endpoint is required by zipkin reporter")
+		}
+
+		transport, err := jaeger_zipkin_transport.NewHTTPTransport(c.CollectorEndpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		reporterOpts := []jaeger_go.ReporterOption{jaeger_go.ReporterOptions.Logger(logger{})}
+		if c.BufferFlushInterval > 0 {
+			reporterOpts = append(reporterOpts, jaeger_go.ReporterOptions.BufferFlushInterval(c.BufferFlushInterval))
+		}
+		if c.QueueSize > 0 {
+			reporterOpts = append(reporterOpts, jaeger_go.ReporterOptions.QueueSize(c.QueueSize))
+		}
+		reporter = jaeger_go.NewRemoteReporter(transport, reporterOpts...)
+		if c.LogSpans {
+			reporter = jaeger_go.NewCompositeReporter(jaeger_go.NewLoggingReporter(logger{}), reporter)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown reporter: '%s'", c.Reporter)
+	}
+
+	if reporter != nil {
+		opts = append(opts, jaeger_cfg.Reporter(reporter))
+	}
+
+	if len(c.Operations) > 0 || c.SampleOnError {
+		def, err := jcfg.Sampler.NewSampler(serviceName, jaeger_go.NewNullMetrics())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		s, err := newSampler(def, c.Operations, c.SampleOnError, reporter)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, jaeger_cfg.Sampler(s))
+	}
+
 	tracer, closer, err = jcfg.New(
 		serviceName,
+		opts...,
 	)
 	if err != nil {
 		return
@@ -0,0 +1,26 @@
+package jaeger
+
+import (
+	"testing"
+)
+
+func TestNewTracerConfigError(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Config
+	}{
+		{name: "no config", c: nil},
+		{name: "unknown propagation", c: &Config{Type: "const", TypeParam: 1, Propagation: "w3c"}},
+		{name: "unknown reporter", c: &Config{Type: "const", TypeParam: 1, Reporter: "kafka"}},
+		{name: "zipkin without collector_endpoint", c: &Config{Type: "const", TypeParam: 1, Reporter: ReporterZipkin}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, closer, err := NewTracer("test", tt.c); err == nil {
+				closer.Close()
+				t.Error("want error")
+			}
+		})
+	}
+}
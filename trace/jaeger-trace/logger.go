@@ -0,0 +1,18 @@
+package jaeger
+
+import (
+	"github.com/robert-pkg/micro-go/log"
+)
+
+// logger adapts log.DefaultLogger to jaeger.Logger, for spans logged by LogSpans and reporter errors.
+type logger struct{}
+
+// Error implements jaeger.Logger
+func (logger) Error(msg string) {
+	log.Error("jaeger: " + msg)
+}
+
+// Infof implements jaeger.Logger
+func (logger) Infof(msg string, args ...interface{}) {
+	log.Infof("jaeger: "+msg, args...)
+}
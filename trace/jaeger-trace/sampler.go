@@ -0,0 +1,200 @@
+package jaeger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opentracing/opentracing-go/ext"
+
+	jaeger_go "github.com/uber/jaeger-client-go"
+	jaeger_cfg "github.com/uber/jaeger-client-go/config"
+)
+
+// OperationSampling sampling rule of operations, an operation is grpc full method or http path.
+type OperationSampling struct {
+	// Operation 精确匹配, 以 * 结尾时匹配前缀, e.g. "/grpc.health.v1.Health/*"
+	Operation string  `yaml:"operation"`
+	Type      string  `yaml:"type"` // const, rateLimiting, probabilistic
+	Param     float64 `yaml:"param"`
+}
+
+// maxBufferedSpans spans of an undecided trace kept in memory, waiting for an error
+const maxBufferedSpans = 256
+
+type prefixSampler struct {
+	prefix  string
+	sampler jaeger_go.Sampler
+}
+
+// sampler 按 operation 选择 sampler, 只对本进程开始的 trace 生效, 有 parent 的 trace 沿用上游的采样结果.
+// sampleOnError 时没有被采样的 trace 暂不确定, 本进程内结束的 span 先缓存,
+// 出错时整个 trace 改为采样并上报缓存的 span, local root span 结束时仍未出错则丢弃.
+type sampler struct {
+	jaeger_go.SamplerV2Base
+
+	def      jaeger_go.Sampler
+	exact    map[string]jaeger_go.Sampler
+	prefixes []prefixSampler // 长的前缀优先
+
+	sampleOnError bool
+	reporter      jaeger_go.Reporter
+}
+
+type errorBufferKey struct{}
+
+// errorBuffer finished spans of an undecided trace
+type errorBuffer struct {
+	mu    sync.Mutex
+	spans []*jaeger_go.Span
+	done  bool // decided, spans are reported or dropped
+}
+
+func newSampler(def jaeger_go.Sampler, rules []OperationSampling, sampleOnError bool, reporter jaeger_go.Reporter) (*sampler, error) {
+	s := &sampler{
+		def:           def,
+		exact:         make(map[string]jaeger_go.Sampler),
+		sampleOnError: sampleOnError,
+		reporter:      reporter,
+	}
+
+	for _, rule := range rules {
+		cfg := jaeger_cfg.SamplerConfig{Type: rule.Type, Param: rule.Param}
+		if len(cfg.Type) <= 0 {
+			// 没有指定类型时为 probabilistic, 避免使用 remote sampler
+			cfg.Type = jaeger_go.SamplerTypeProbabilistic
+		}
+
+		rs, err := cfg.NewSampler("", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(rule.Operation, "*") {
+			s.prefixes = append(s.prefixes, prefixSampler{prefix: strings.TrimSuffix(rule.Operation, "*"), sampler: rs})
+		} else {
+			s.exact[rule.Operation] = rs
+		}
+	}
+
+	sort.SliceStable(s.prefixes, func(i, j int) bool {
+		return len(s.prefixes[i].prefix) > len(s.prefixes[j].prefix)
+	})
+
+	return s, nil
+}
+
+func (s *sampler) samplerOf(operation string) jaeger_go.Sampler {
+	if rs, ok := s.exact[operation]; ok {
+		return rs
+	}
+
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(operation, p.prefix) {
+			return p.sampler
+		}
+	}
+
+	return s.def
+}
+
+// isLocalRoot 未确定采样的 trace 都是本进程开始的, root span 没有 parent
+func isLocalRoot(span *jaeger_go.Span) bool {
+	return span.SpanContext().ParentID() == 0
+}
+
+// undecided not sampled, and wait for an error if sampleOnError
+func (s *sampler) undecided() jaeger_go.SamplingDecision {
+	return jaeger_go.SamplingDecision{Sample: false, Retryable: s.sampleOnError}
+}
+
+// OnCreateSpan implements jaeger.SamplerV2
+func (s *sampler) OnCreateSpan(span *jaeger_go.Span) jaeger_go.SamplingDecision {
+	if !isLocalRoot(span) {
+		// root span 已经决定为不采样, 等待出错
+		return s.undecided()
+	}
+
+	sampled, tags := s.samplerOf(span.OperationName()).IsSampled(span.SpanContext().TraceID(), span.OperationName())
+	if sampled {
+		return jaeger_go.SamplingDecision{Sample: true, Tags: tags}
+	}
+	return s.undecided()
+}
+
+// OnSetOperationName implements jaeger.SamplerV2
+func (s *sampler) OnSetOperationName(span *jaeger_go.Span, operationName string) jaeger_go.SamplingDecision {
+	return s.undecided()
+}
+
+// OnSetTag implements jaeger.SamplerV2
+func (s *sampler) OnSetTag(span *jaeger_go.Span, key string, value interface{}) jaeger_go.SamplingDecision {
+	if !s.sampleOnError || key != string(ext.Error) {
+		return s.undecided()
+	}
+
+	if isErr, _ := value.(bool); !isErr {
+		return s.undecided()
+	}
+
+	s.flush(s.buffer(span), true)
+	return jaeger_go.SamplingDecision{
+		Sample: true,
+		Tags:   []jaeger_go.Tag{jaeger_go.NewTag(jaeger_go.SamplerTypeTagKey, "error")},
+	}
+}
+
+// OnFinishSpan implements jaeger.SamplerV2
+func (s *sampler) OnFinishSpan(span *jaeger_go.Span) jaeger_go.SamplingDecision {
+	if !s.sampleOnError {
+		return s.undecided()
+	}
+
+	buf := s.buffer(span)
+	if isLocalRoot(span) {
+		s.flush(buf, false)
+		return jaeger_go.SamplingDecision{Sample: false}
+	}
+
+	buf.mu.Lock()
+	if !buf.done && len(buf.spans) < maxBufferedSpans {
+		// span 上报或丢弃之后会被 Release, 需要保留到 trace 确定
+		buf.spans = append(buf.spans, span.Retain())
+	}
+	buf.mu.Unlock()
+
+	return s.undecided()
+}
+
+func (s *sampler) buffer(span *jaeger_go.Span) *errorBuffer {
+	return span.SpanContext().ExtendedSamplingState(errorBufferKey{}, func() interface{} {
+		return &errorBuffer{}
+	}).(*errorBuffer)
+}
+
+// flush report buffered spans if sampled, otherwise drop them
+func (s *sampler) flush(buf *errorBuffer, sampled bool) {
+	buf.mu.Lock()
+	spans := buf.spans
+	buf.spans = nil
+	buf.done = true
+	buf.mu.Unlock()
+
+	for _, sp := range spans {
+		if sampled && s.reporter != nil {
+			s.reporter.Report(sp)
+		}
+		sp.Release()
+	}
+}
+
+// Close implements jaeger.SamplerV2
+func (s *sampler) Close() {
+	s.def.Close()
+	for _, rs := range s.exact {
+		rs.Close()
+	}
+	for _, p := range s.prefixes {
+		p.sampler.Close()
+	}
+}
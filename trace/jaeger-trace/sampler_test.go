@@ -0,0 +1,197 @@
+package jaeger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	jaeger_go "github.com/uber/jaeger-client-go"
+
+	"github.com/robert-pkg/micro-go/rpc"
+	"github.com/robert-pkg/micro-go/trace"
+)
+
+func newTestTracer(t *testing.T, def jaeger_go.Sampler, rules []OperationSampling, sampleOnError bool) (opentracing.Tracer, *sampler, *jaeger_go.InMemoryReporter) {
+	t.Helper()
+
+	reporter := jaeger_go.NewInMemoryReporter()
+	s, err := newSampler(def, rules, sampleOnError, reporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer, closer := jaeger_go.NewTracer("test", s, reporter)
+	t.Cleanup(func() { closer.Close() })
+	return tracer, s, reporter
+}
+
+func TestOperationRules(t *testing.T) {
+	never := jaeger_go.NewConstSampler(false)
+
+	tests := []struct {
+		name  string
+		rules []OperationSampling
+		op    string
+		want  bool
+	}{
+		{
+			name:  "default",
+			rules: []OperationSampling{{Operation: "/a/b", Type: "const", Param: 1}},
+			op:    "/a/c",
+			want:  false,
+		},
+		{
+			name: "exact wins over prefix",
+			rules: []OperationSampling{
+				{Operation: "/a/*", Type: "const", Param: 1},
+				{Operation: "/a/b", Type: "const", Param: 0},
+			},
+			op:   "/a/b",
+			want: false,
+		},
+		{
+			name: "prefix",
+			rules: []OperationSampling{
+				{Operation: "/a/*", Type: "const", Param: 1},
+				{Operation: "/a/b", Type: "const", Param: 0},
+			},
+			op:   "/a/c",
+			want: true,
+		},
+		{
+			name: "longest prefix wins",
+			rules: []OperationSampling{
+				{Operation: "/a/*", Type: "const", Param: 1},
+				{Operation: "/a/b/*", Type: "const", Param: 0},
+			},
+			op:   "/a/b/c",
+			want: false,
+		},
+		{
+			name: "longest prefix wins in any order",
+			rules: []OperationSampling{
+				{Operation: "/a/b/*", Type: "const", Param: 1},
+				{Operation: "/a/*", Type: "const", Param: 0},
+			},
+			op:   "/a/b/c",
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, _, reporter := newTestTracer(t, never, tt.rules, false)
+
+			span := tracer.StartSpan(tt.op)
+			sampled := span.Context().(jaeger_go.SpanContext).IsSampled()
+			span.Finish()
+
+			if sampled != tt.want {
+				t.Errorf("sampled: got %v, want %v", sampled, tt.want)
+			}
+			if n := reporter.SpansSubmitted(); (n == 1) != tt.want {
+				t.Errorf("reported %d spans", n)
+			}
+		})
+	}
+}
+
+func TestSampleOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		children int  // finished children before the error, or the end
+		errChild bool // a child fails after them
+		want     int  // spans reported
+	}{
+		{name: "no error", children: 3, errChild: false, want: 0},
+		{name: "error on child", children: 3, errChild: true, want: 3 + 2},
+		{name: "buffer is capped", children: maxBufferedSpans + 10, errChild: true, want: maxBufferedSpans + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, s, reporter := newTestTracer(t, jaeger_go.NewConstSampler(false), nil, true)
+
+			root := tracer.StartSpan("root")
+			for i := 0; i < tt.children; i++ {
+				tracer.StartSpan("child", opentracing.ChildOf(root.Context())).Finish()
+			}
+			if tt.errChild {
+				child := tracer.StartSpan("fail", opentracing.ChildOf(root.Context()))
+				ext.Error.Set(child, true)
+				child.Finish()
+			}
+
+			buf := s.buffer(root.(*jaeger_go.Span))
+			root.Finish()
+
+			if n := reporter.SpansSubmitted(); n != tt.want {
+				t.Errorf("reported: got %d, want %d", n, tt.want)
+			}
+
+			// 每个 span 只上报一次
+			seen := make(map[jaeger_go.SpanID]bool)
+			for _, sp := range reporter.GetSpans() {
+				id := sp.Context().(jaeger_go.SpanContext).SpanID()
+				if seen[id] {
+					t.Errorf("span %v reported more than once", id)
+				}
+				seen[id] = true
+			}
+
+			// 已确定, 缓存的 span 已上报或丢弃并 Release
+			buf.mu.Lock()
+			defer buf.mu.Unlock()
+			if !buf.done || len(buf.spans) != 0 {
+				t.Errorf("buffer: done %v, %d spans left", buf.done, len(buf.spans))
+			}
+		})
+	}
+}
+
+func TestTraceDebug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "not sampled", header: "", want: 0},
+		{name: "Trace-Debug", header: "true", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, _, reporter := newTestTracer(t, jaeger_go.NewConstSampler(false), nil, true)
+
+			old := opentracing.GlobalTracer()
+			opentracing.SetGlobalTracer(tracer)
+			defer opentracing.SetGlobalTracer(old)
+
+			e := gin.New()
+			e.Use(trace.SetUpTraceForGinServer())
+			e.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+			req := httptest.NewRequest(http.MethodGet, "/ping?token=secret", nil)
+			if len(tt.header) > 0 {
+				req.Header.Set(rpc.TraceDebug, tt.header)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			if n := reporter.SpansSubmitted(); n != tt.want {
+				t.Errorf("reported: got %d, want %d", n, tt.want)
+			}
+
+			// query 不记录到 span
+			for _, sp := range reporter.GetSpans() {
+				if url := sp.(*jaeger_go.Span).Tags()[string(ext.HTTPUrl)]; url != "/ping" {
+					t.Errorf("http.url: %v", url)
+				}
+			}
+		})
+	}
+}
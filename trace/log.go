@@ -0,0 +1,22 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/robert-pkg/micro-go/log"
+)
+
+func init() {
+	// log.XxxContext 时自动带上 trace_id, span_id
+	log.RegisterContextExtractor(logFields)
+}
+
+// logFields return trace_id and span_id of the span carried by ctx.
+func logFields(ctx context.Context) []interface{} {
+	traceID, spanID, ok := IDs(SpanContextFromContext(ctx))
+	if !ok {
+		return nil
+	}
+
+	return []interface{}{"trace_id", traceID, "span_id", spanID}
+}
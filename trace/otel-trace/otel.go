@@ -0,0 +1,110 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	otel_go "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// instrumentationName name of the otel tracer
+const instrumentationName = "github.com/robert-pkg/micro-go"
+
+// Config .
+type Config struct {
+	Endpoint     string        `yaml:"endpoint"`      // otlp collector, e.g. 127.0.0.1:4317 for grpc, 127.0.0.1:4318 for http
+	Protocol     string        `yaml:"protocol"`      // grpc(default), http
+	Insecure     bool          `yaml:"insecure"`      // no tls
+	SampleRatio  float64       `yaml:"sample_ratio"`  // 0-1.0, 为0时全采样
+	BatchTimeout time.Duration `yaml:"batch_timeout"` // default 5s
+	QueueSize    int           `yaml:"queue_size"`    // span queue size in memory, default 2048
+}
+
+// NewTracer for current service, spans are exported by otlp and propagated by W3C traceparent.
+// the returned tracer implements opentracing.Tracer, so interceptors, gin middleware and rpc clients work as is.
+func NewTracer(serviceName string, c *Config) (tracer opentracing.Tracer, closer io.Closer, err error) {
+
+	if c == nil {
+		return nil, nil, errors.New("no trace config")
+	}
+
+	exporter, err := newExporter(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var batchOpts []sdktrace.BatchSpanProcessorOption
+	if c.BatchTimeout > 0 {
+		batchOpts = append(batchOpts, sdktrace.WithBatchTimeout(c.BatchTimeout))
+	}
+	if c.QueueSize > 0 {
+		batchOpts = append(batchOpts, sdktrace.WithMaxQueueSize(c.QueueSize))
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if c.SampleRatio > 0 && c.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(c.SampleRatio)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, batchOpts...),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		// sampling.priority, e.g. Trace-Debug, 优先于 parent 和 SampleRatio
+		sdktrace.WithSampler(prioritySampler{sdktrace.ParentBased(sampler)}),
+	)
+
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+	// 同时设置 otel 的全局对象, 以便直接使用 otel api 的代码也能串起来
+	otel_go.SetTracerProvider(tp)
+	otel_go.SetTextMapPropagator(propagator)
+
+	tracer = Wrap(tp.Tracer(instrumentationName), propagator)
+
+	// 设置为全局的单例tracer
+	opentracing.SetGlobalTracer(tracer)
+
+	return tracer, closerFunc(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return tp.Shutdown(ctx)
+	}), nil
+}
+
+func newExporter(c *Config) (*otlptrace.Exporter, error) {
+	ctx := context.Background()
+
+	switch c.Protocol {
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.Endpoint)}
+		if c.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(c.Endpoint)}
+		if c.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, errors.New("unknown otlp protocol: " + c.Protocol)
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,40 @@
+package otel
+
+import (
+	"github.com/opentracing/opentracing-go/ext"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// prioritySampler honours the opentracing tag sampling.priority given when a span starts, e.g. by Trace-Debug,
+// > 0 samples and 0 drops, otherwise the wrapped sampler decides.
+// otel can not change the decision after started, so the tag set by SetTag is only recorded as an attribute.
+type prioritySampler struct {
+	sdktrace.Sampler
+}
+
+// ShouldSample implements sdktrace.Sampler
+func (s prioritySampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, attr := range p.Attributes {
+		// toAttribute 把整数 tag 转换为 INT64
+		if string(attr.Key) != string(ext.SamplingPriority) || attr.Value.Type() != attribute.INT64 {
+			continue
+		}
+
+		priority := attr.Value.AsInt64()
+		ts := oteltrace.SpanContextFromContext(p.ParentContext).TraceState()
+		if priority > 0 {
+			return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: ts}
+		}
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: ts}
+	}
+
+	return s.Sampler.ShouldSample(p)
+}
+
+// Description implements sdktrace.Sampler
+func (s prioritySampler) Description() string {
+	return "PrioritySampler{" + s.Sampler.Description() + "}"
+}
@@ -0,0 +1,376 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	opentracelog "github.com/opentracing/opentracing-go/log"
+
+	"go.opentelemetry.io/otel/attribute"
+	otel_baggage "go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// tracer implements opentracing.Tracer on top of an otel tracer
+type tracer struct {
+	tracer     oteltrace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// Wrap return opentracing.Tracer creating spans by t, and propagating span contexts by p.
+func Wrap(t oteltrace.Tracer, p propagation.TextMapPropagator) opentracing.Tracer {
+	return &tracer{tracer: t, propagator: p}
+}
+
+// SpanContext implements opentracing.SpanContext
+type SpanContext struct {
+	sc      oteltrace.SpanContext
+	baggage map[string]string // read only
+}
+
+// OTel return the otel span context
+func (c SpanContext) OTel() oteltrace.SpanContext {
+	return c.sc
+}
+
+// ForeachBaggageItem implements opentracing.SpanContext
+func (c SpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.baggage {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+// TraceIDString implements trace.SpanContextIDs
+func (c SpanContext) TraceIDString() string {
+	return c.sc.TraceID().String()
+}
+
+// SpanIDString implements trace.SpanContextIDs
+func (c SpanContext) SpanIDString() string {
+	return c.sc.SpanID().String()
+}
+
+// IsValid .
+func (c SpanContext) IsValid() bool {
+	return c.sc.IsValid()
+}
+
+// IsSampled .
+func (c SpanContext) IsSampled() bool {
+	return c.sc.IsSampled()
+}
+
+func (t *tracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+
+	ctx := context.Background()
+	baggage := make(map[string]string)
+	var links []oteltrace.Link
+	hasParent := false
+	for _, ref := range sso.References {
+		sc, ok := ref.ReferencedContext.(SpanContext)
+		if !ok || !sc.sc.IsValid() {
+			continue
+		}
+
+		for k, v := range sc.baggage {
+			baggage[k] = v
+		}
+
+		// 第一个 ChildOf 作为 parent, 其它的作为 link
+		if ref.Type == opentracing.ChildOfRef && !hasParent {
+			// 本进程内的 parent 不能标记为 remote, 否则 ParentBased sampler 按 remote parent 采样
+			if sc.sc.IsRemote() {
+				ctx = oteltrace.ContextWithRemoteSpanContext(ctx, sc.sc)
+			} else {
+				ctx = oteltrace.ContextWithSpanContext(ctx, sc.sc)
+			}
+			hasParent = true
+		} else {
+			links = append(links, oteltrace.Link{SpanContext: sc.sc})
+		}
+	}
+
+	kind := oteltrace.SpanKindInternal
+	attrs := make([]attribute.KeyValue, 0, len(sso.Tags))
+	isErr := false
+	for k, v := range sso.Tags {
+		switch k {
+		case string(ext.SpanKind):
+			kind = toSpanKind(v)
+		case string(ext.Error):
+			isErr, _ = v.(bool)
+		default:
+			attrs = append(attrs, toAttribute(k, v))
+		}
+	}
+
+	startOpts := []oteltrace.SpanStartOption{
+		oteltrace.WithSpanKind(kind),
+		oteltrace.WithAttributes(attrs...),
+		oteltrace.WithLinks(links...),
+	}
+	if !sso.StartTime.IsZero() {
+		startOpts = append(startOpts, oteltrace.WithTimestamp(sso.StartTime))
+	}
+
+	_, s := t.tracer.Start(ctx, operationName, startOpts...)
+	if isErr {
+		s.SetStatus(codes.Error, "")
+	}
+
+	return &span{tracer: t, span: s, baggage: baggage}
+}
+
+func (t *tracer) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	sc, ok := sm.(SpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+
+	if format != opentracing.TextMap && format != opentracing.HTTPHeaders {
+		return opentracing.ErrUnsupportedFormat
+	}
+
+	w, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), sc.sc)
+	if len(sc.baggage) > 0 {
+		members := make([]otel_baggage.Member, 0, len(sc.baggage))
+		for k, v := range sc.baggage {
+			if m, err := otel_baggage.NewMember(k, v); err == nil {
+				members = append(members, m)
+			}
+		}
+		if b, err := otel_baggage.New(members...); err == nil {
+			ctx = otel_baggage.ContextWithBaggage(ctx, b)
+		}
+	}
+
+	t.propagator.Inject(ctx, writerCarrier{w})
+	return nil
+}
+
+func (t *tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	if format != opentracing.TextMap && format != opentracing.HTTPHeaders {
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+
+	r, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	// http header 是首字母大写的, propagator 使用小写的key
+	m := make(propagation.MapCarrier)
+	err := r.ForeachKey(func(key, val string) error {
+		m[strings.ToLower(key)] = val
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := t.propagator.Extract(context.Background(), m)
+	sc := oteltrace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	baggage := make(map[string]string)
+	for _, member := range otel_baggage.FromContext(ctx).Members() {
+		baggage[member.Key()] = member.Value()
+	}
+
+	return SpanContext{sc: sc, baggage: baggage}, nil
+}
+
+// writerCarrier adapts opentracing.TextMapWriter to propagation.TextMapCarrier, used by Inject only
+type writerCarrier struct {
+	opentracing.TextMapWriter
+}
+
+func (c writerCarrier) Get(key string) string {
+	return ""
+}
+
+func (c writerCarrier) Keys() []string {
+	return nil
+}
+
+// span implements opentracing.Span
+type span struct {
+	tracer *tracer
+	span   oteltrace.Span
+
+	mu      sync.Mutex
+	baggage map[string]string
+}
+
+func (s *span) Finish() {
+	s.span.End()
+}
+
+func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
+	for _, lr := range opts.LogRecords {
+		s.addEvent(lr.Fields, oteltrace.WithTimestamp(lr.Timestamp))
+	}
+
+	if opts.FinishTime.IsZero() {
+		s.span.End()
+	} else {
+		s.span.End(oteltrace.WithTimestamp(opts.FinishTime))
+	}
+}
+
+func (s *span) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	baggage := make(map[string]string, len(s.baggage))
+	for k, v := range s.baggage {
+		baggage[k] = v
+	}
+	s.mu.Unlock()
+
+	return SpanContext{sc: s.span.SpanContext(), baggage: baggage}
+}
+
+func (s *span) SetOperationName(operationName string) opentracing.Span {
+	s.span.SetName(operationName)
+	return s
+}
+
+func (s *span) SetTag(key string, value interface{}) opentracing.Span {
+	if key == string(ext.Error) {
+		if isErr, _ := value.(bool); isErr {
+			s.span.SetStatus(codes.Error, "")
+		}
+		return s
+	}
+
+	s.span.SetAttributes(toAttribute(key, value))
+	return s
+}
+
+func (s *span) LogFields(fields ...opentracelog.Field) {
+	s.addEvent(fields)
+}
+
+func (s *span) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := opentracelog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(opentracelog.Error(err), opentracelog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// addEvent event name is the "event" field, or "log"
+func (s *span) addEvent(fields []opentracelog.Field, opts ...oteltrace.EventOption) {
+	name := "log"
+	attrs := make([]attribute.KeyValue, 0, len(fields))
+	for _, f := range fields {
+		if f.Key() == "event" {
+			name = fmt.Sprint(f.Value())
+			continue
+		}
+		attrs = append(attrs, toAttribute(f.Key(), f.Value()))
+	}
+
+	opts = append(opts, oteltrace.WithAttributes(attrs...))
+	s.span.AddEvent(name, opts...)
+}
+
+func (s *span) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	s.baggage[restrictedKey] = value
+	s.mu.Unlock()
+	return s
+}
+
+func (s *span) BaggageItem(restrictedKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.baggage[restrictedKey]
+}
+
+func (s *span) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+func (s *span) LogEvent(event string) {
+	s.LogFields(opentracelog.String("event", event))
+}
+
+func (s *span) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(opentracelog.String("event", event), opentracelog.Object("payload", payload))
+}
+
+func (s *span) Log(data opentracing.LogData) {
+	s.addEvent(data.ToLogRecord().Fields, oteltrace.WithTimestamp(data.Timestamp))
+}
+
+func toSpanKind(v interface{}) oteltrace.SpanKind {
+	var kind string
+	switch k := v.(type) {
+	case ext.SpanKindEnum:
+		kind = string(k)
+	case string:
+		kind = k
+	}
+
+	switch ext.SpanKindEnum(kind) {
+	case ext.SpanKindRPCClientEnum:
+		return oteltrace.SpanKindClient
+	case ext.SpanKindRPCServerEnum:
+		return oteltrace.SpanKindServer
+	case ext.SpanKindProducerEnum:
+		return oteltrace.SpanKindProducer
+	case ext.SpanKindConsumerEnum:
+		return oteltrace.SpanKindConsumer
+	default:
+		return oteltrace.SpanKindInternal
+	}
+}
+
+func toAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint16:
+		return attribute.Int64(key, int64(v))
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case uint64:
+		return attribute.Int64(key, int64(v))
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
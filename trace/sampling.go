@@ -0,0 +1,24 @@
+package trace
+
+import (
+	"strconv"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/robert-pkg/micro-go/rpc"
+)
+
+// samplingPriority 请求中 rpc.TraceDebug 为 true 时强制采样, 优先于 sampler, rpc.SkipTrace 见 IsSkipped.
+// get 读取 header 或 metadata, ok 为 false 时由 sampler 决定.
+func samplingPriority(get func(key string) string) (tag opentracing.Tag, ok bool) {
+	if isTrue(get(rpc.TraceDebug)) {
+		return opentracing.Tag{Key: string(ext.SamplingPriority), Value: uint16(1)}, true
+	}
+
+	return opentracing.Tag{}, false
+}
+
+func isTrue(v string) bool {
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
@@ -0,0 +1,35 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/robert-pkg/micro-go/rpc"
+	rpc_metadata "github.com/robert-pkg/micro-go/rpc/metadata"
+	"google.golang.org/grpc/metadata"
+)
+
+// IsSkipped 请求带有 rpc.SkipTrace 时为 true, 不创建 span, 也不向下游注入 trace.
+// rpc.SkipTrace 保存在 rpc metadata 中, 由 rpc client 传给下游, 下游同样跳过.
+func IsSkipped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	if v, ok := rpc_metadata.Get(ctx, rpc.SkipTrace); ok {
+		return isTrue(v)
+	}
+
+	// 没有经过 metadataInterceptor 的 grpc server
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(rpc.SkipTrace); len(v) > 0 {
+			return isTrue(v[0])
+		}
+	}
+
+	return false
+}
+
+// WithSkip mark ctx as rpc.SkipTrace, e.g. calls of synthetic probes.
+func WithSkip(ctx context.Context) context.Context {
+	return rpc_metadata.Set(ctx, rpc.SkipTrace, "true")
+}
@@ -0,0 +1,37 @@
+package trace
+
+import (
+	"unicode/utf8"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	opentracelog "github.com/opentracing/opentracing-go/log"
+)
+
+// tags of server spans
+const (
+	TagGRPCCode  = "grpc.code"
+	TagErrorCode = "error.code" // ecode, int32 for both grpc and http
+)
+
+// SetError mark span as error and log err, nothing is done if err is nil.
+func SetError(span opentracing.Span, err error) {
+	if span == nil || err == nil {
+		return
+	}
+
+	ext.Error.Set(span, true)
+	span.LogFields(opentracelog.Error(err))
+}
+
+// Truncate s to at most max bytes for a tag value, e.g. db.statement, cut on a rune boundary and end with "...".
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + "..."
+}